@@ -0,0 +1,79 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/siemens/mobydig/dig"
+
+	"github.com/spf13/cobra"
+)
+
+func newDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff [flags] old.json new.json",
+		Short: "diff compares the JSON results of two mobydig runs and fails on quality regressions",
+		Args:  cobra.ExactArgs(2),
+		// Usage doesn't help with unreadable files or regressions.
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			before, err := readJSON(args[0])
+			if err != nil {
+				return err
+			}
+			after, err := readJSON(args[1])
+			if err != nil {
+				return err
+			}
+			diff := dig.Diff(before, after)
+			reportDiff(cmd.OutOrStdout(), diff)
+			if regressions := len(diff.Regressions()); regressions != 0 {
+				return fmt.Errorf("%d address quality regression(s)", regressions)
+			}
+			return nil
+		},
+	}
+}
+
+// readJSON reads named address sets from the specified JSON file, as written
+// by the --json flag.
+func readJSON(filename string) ([]dig.NamedAddressSet, error) {
+	j, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read results: %w", err)
+	}
+	var sets []dig.NamedAddressSet
+	if err := json.Unmarshal(j, &sets); err != nil {
+		return nil, fmt.Errorf("cannot read results from %s: %w", filename, err)
+	}
+	return sets, nil
+}
+
+// reportDiff writes the specified snapshot differences to w, one line per
+// difference.
+func reportDiff(w io.Writer, diff *dig.SnapshotDiff) {
+	for _, fqdn := range diff.Appeared {
+		fmt.Fprintf(w, "+ %s\n", fqdn)
+	}
+	for _, fqdn := range diff.Disappeared {
+		fmt.Fprintf(w, "- %s\n", fqdn)
+	}
+	for _, change := range diff.Changes {
+		switch change.Kind {
+		case dig.AddressAppeared:
+			fmt.Fprintf(w, "+ %s %s (%s)\n", change.FQDN, change.Address, change.New)
+		case dig.AddressDisappeared:
+			fmt.Fprintf(w, "- %s %s (%s)\n", change.FQDN, change.Address, change.Old)
+		case dig.QualityImproved:
+			fmt.Fprintf(w, "↑ %s %s %s → %s\n", change.FQDN, change.Address, change.Old, change.New)
+		case dig.QualityRegressed:
+			fmt.Fprintf(w, "↓ %s %s %s → %s\n", change.FQDN, change.Address, change.Old, change.New)
+		}
+	}
+}
@@ -7,6 +7,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -18,6 +21,24 @@ var (
 	spinnerInterval *time.Duration
 	workerNumber    *uint
 	debug           *bool
+	reverse         *bool
+	externals       *[]string
+	externalPort    *uint16
+	maxRTT          *time.Duration
+	jsonOutput      *string
+	hosts           *bool
+	nameMode        *string
+	timeout         *time.Duration
+	watch           *time.Duration
+
+	networks               *[]string
+	excludeNetworks        *[]string
+	containerLabels        *[]string
+	excludeContainerLabels *[]string
+	projects               *[]string
+	excludeProjects        *[]string
+	names                  *string
+	excludeNames           *string
 )
 
 func newRootCmd() (rootCmd *cobra.Command) {
@@ -36,6 +57,12 @@ func newRootCmd() (rootCmd *cobra.Command) {
 			if *spinnerInterval < 10*time.Millisecond {
 				return fmt.Errorf("--spinner must be at least 10ms")
 			}
+			if *timeout < 0 {
+				return fmt.Errorf("--timeout must not be negative")
+			}
+			if *watch < 0 {
+				return fmt.Errorf("--watch must not be negative")
+			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -43,7 +70,17 @@ func newRootCmd() (rootCmd *cobra.Command) {
 				log.SetLevel(log.DebugLevel)
 				log.Debugf("debug logging enabled")
 			}
-			return DigAndReport(context.Background(), args[0])
+			// Cancel digging upon SIGINT and SIGTERM, as well as after the
+			// timeout, if any, so that the (partial) results still get
+			// rendered a final time.
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if *timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, *timeout)
+				defer cancel()
+			}
+			return DigAndReport(ctx, args[0])
 		},
 	}
 	// Sets up the flags.
@@ -55,5 +92,40 @@ func newRootCmd() (rootCmd *cobra.Command) {
 		"spinner", 100*time.Millisecond, "spinner interval")
 	workerNumber = rootCmd.PersistentFlags().Uint(
 		"workers", 5, "number of DNS and ping workers")
+	externals = rootCmd.PersistentFlags().StringSlice(
+		"external", nil, "external names to additionally dig, such as registry host names")
+	externalPort = rootCmd.PersistentFlags().Uint16(
+		"external-port", 0, "TCP port to probe the addresses of external names instead of pinging them")
+	maxRTT = rootCmd.PersistentFlags().Duration(
+		"max-rtt", 0, "average round-trip time above which addresses are considered to be degraded (0 to disable)")
+	reverse = rootCmd.PersistentFlags().Bool(
+		"reverse", false, "check that addresses map back to their names using reverse (PTR) lookups")
+	hosts = rootCmd.PersistentFlags().Bool(
+		"hosts", true, "verify the names from the /etc/hosts of the container, such as \"extra_hosts\"")
+	networks = rootCmd.PersistentFlags().StringSlice(
+		"network", nil, "only dig names on networks matching any of these glob patterns")
+	excludeNetworks = rootCmd.PersistentFlags().StringSlice(
+		"exclude-network", nil, "skip networks matching any of these glob patterns")
+	containerLabels = rootCmd.PersistentFlags().StringSlice(
+		"label", nil, "only dig names of containers with any of these labels, as key or key=value")
+	excludeContainerLabels = rootCmd.PersistentFlags().StringSlice(
+		"exclude-label", nil, "skip names of containers with any of these labels, as key or key=value")
+	projects = rootCmd.PersistentFlags().StringSlice(
+		"project", nil, "only dig names of containers belonging to any of these compose projects")
+	excludeProjects = rootCmd.PersistentFlags().StringSlice(
+		"exclude-project", nil, "skip names of containers belonging to any of these compose projects")
+	names = rootCmd.PersistentFlags().String(
+		"names", "", "only dig container names and aliases matching this regular expression")
+	excludeNames = rootCmd.PersistentFlags().String(
+		"exclude-names", "", "skip container names and aliases matching this regular expression")
+	nameMode = rootCmd.Flags().String(
+		"name-mode", "both", "which names to dig: 'qualified' with network names, 'bare', or 'both'")
+	timeout = rootCmd.Flags().Duration(
+		"timeout", 0, "maximum duration of the whole run, after which the results are incomplete (0 for no limit)")
+	watch = rootCmd.Flags().Duration(
+		"watch", 0, "repeat digging after this interval until interrupted, reusing the inspections of unchanged containers and networks (0 to dig only once)")
+	jsonOutput = rootCmd.Flags().String(
+		"json", "", "write the final results as JSON to the specified file, for use with \"mobydig diff\"")
+	rootCmd.AddCommand(newDiffCmd())
 	return
 }
@@ -9,7 +9,21 @@ import "github.com/muesli/termenv"
 var (
 	verifyingAddressStyle = termenv.Style{}.Foreground(termenv.ANSIYellow)
 	validAddressStyle     = termenv.Style{}.Foreground(termenv.ANSIGreen)
+	degradedAddressStyle  = termenv.Style{}.Foreground(termenv.ANSIBrightYellow)
 	invalidAddressStyle   = termenv.Style{}.Foreground(termenv.ANSIRed)
+	reverseMismatchStyle  = termenv.Style{}.Foreground(termenv.ANSIMagenta)
+	shadowedDNSStyle      = termenv.Style{}.Foreground(termenv.ANSIMagenta)
+	misconfigurationStyle = termenv.Style{}.Foreground(termenv.ANSIMagenta)
+	expectedFailureStyle  = termenv.Style{}.Faint()
 )
 
-var networkNameStyle = termenv.Style{}.Bold()
+var (
+	networkNameStyle = termenv.Style{}.Bold()
+	networkInfoStyle = termenv.Style{}.Faint()
+	ambiguousStyle   = termenv.Style{}.Foreground(termenv.ANSIBrightYellow)
+	incompleteStyle  = termenv.Style{}.Foreground(termenv.ANSIBrightRed)
+	cnameStyle       = termenv.Style{}.Faint()
+	containerStyle   = termenv.Style{}.Foreground(termenv.ANSICyan)
+	latencyStyle     = termenv.Style{}.Faint()
+	slowLookupStyle  = termenv.Style{}.Foreground(termenv.ANSIRed)
+)
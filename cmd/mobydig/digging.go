@@ -6,15 +6,22 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path"
+	"regexp"
 	"time"
 
 	"github.com/siemens/mobydig/dig"
 	"github.com/siemens/mobydig/mobynet"
-	"github.com/siemens/mobydig/verifier"
+	"github.com/siemens/mobydig/ping"
+	"github.com/siemens/mobydig/pipeline"
 
 	"github.com/docker/docker/client"
 	"github.com/gosuri/uilive"
+	"github.com/miekg/dns"
 )
 
 // DigAndReport locates a “starting point” container by its name and then looks
@@ -22,90 +29,200 @@ import (
 // networks are discovered, and then these (DNS) names dug up from the
 // perspective of the center container. Finally, the addresses are verified by
 // pinging them for good or bad.
+//
+// When the specified context gets cancelled, such as upon SIGINT or after a
+// timeout, digging and verifying stops and the results so far get rendered a
+// final time, marked as being incomplete. DigAndReport then returns an error.
+//
+// In watch mode, DigAndReport repeats digging and reporting after each watch
+// interval until the context gets cancelled. The repeated discoveries share a
+// cache of container and network inspections, which is invalidated by the
+// Docker events.
 func DigAndReport(ctx context.Context, startpointName string) error {
-	cln, err := client.NewClientWithOpts(
-		client.WithHost("unix:///var/run/docker.sock"),
-		client.WithAPIVersionNegotiation(),
-	)
+	discoveryopts, err := discoveryOptions()
 	if err != nil {
-		return fmt.Errorf("cannot connect to the Docker daemon: %w", err)
+		return err
+	}
+	var mode dig.NameMode
+	if err := mode.UnmarshalText([]byte(*nameMode)); err != nil {
+		return fmt.Errorf("--name-mode must be one of 'both', 'qualified', or 'bare'")
+	}
+	opts := []pipeline.Option{
+		pipeline.WithWorkers(int(*workerNumber)),
+		pipeline.WithDiscoveryOptions(discoveryopts...),
+		pipeline.WithDiggerOptions(dig.WithNameMode(mode)),
+		pipeline.WithPingerOptions(ping.WithMaxRTT(*maxRTT)),
+		pipeline.WithExternals(*externals...),
+		pipeline.WithExternalTCPProbe(*externalPort),
+	}
+	if *reverse {
+		opts = append(opts, pipeline.WithDiggerOptions(dig.WithReverseLookups()))
+	}
+	if !*hosts {
+		opts = append(opts, pipeline.WithoutHosts())
 	}
 
-	// Create an empty (concurrency-safe) result map with named-and-qualified
-	// addresses and immediately fire off the rendering goroutine. The rendering
-	// will only stop after tracking has finished because the result stream
-	// channel has been closed. We then render a final update and end rendering,
-	// signalling the end of our activities via renderingDone.
-	namaddrs := dig.NewNamedAddressesMap()
-	trackingDone := make(chan struct{})
-	renderingDone := make(chan struct{})
+	// Dunno what uilive's background updating mode using Start() is good for?
+	// It may trigger anytime with the rendering into the buffer not yet
+	// complete, thus making the terminal output very flickery. So we avoid
+	// Start() and instead trigger an explicit flush to the terminal after
+	// having completed the rendering.
+	term := uilive.New()
+	renderer := newRenderer(term, startpointName)
+	defer renderer.Stop()
+	renderer.Indentation = int(*indentation)
+	renderer.Reverse = *reverse
+	renderer.Externals = map[string]struct{}{}
+	for _, name := range *externals {
+		renderer.Externals[dns.Fqdn(name)] = struct{}{}
+	}
+	renderData(term, renderer, nil)
 
-	go func() {
-		// Dunno what uilive's background updating mode using Start() is good
-		// for? It may trigger anytime with the rendering into the buffer not
-		// yet complete, thus making the terminal output very flickery. So we
-		// avoid Start() and instead trigger an explicit flush to the terminal
-		// after having completed the rendering.
-		term := uilive.New()
-		renderer := newRenderer(term, startpointName)
-		renderer.Indentation = int(*indentation)
-		defer func() {
-			renderData(term, renderer, namaddrs)
-			renderer.Stop()
-			close(renderingDone)
+	if *watch > 0 {
+		moby, err := client.NewClientWithOpts(
+			client.WithHost("unix:///var/run/docker.sock"),
+			client.WithAPIVersionNegotiation(),
+		)
+		if err != nil {
+			return fmt.Errorf("cannot connect to the Docker daemon: %w", err)
+		}
+		defer moby.Close()
+		// Keep the cache watching the Docker events across the runs; should
+		// the event stream fail, then resubscribe, with Watch invalidating
+		// the whole cache as it cannot know what it has missed.
+		cache := mobynet.NewCache()
+		watchctx, cancelWatch := context.WithCancel(ctx)
+		defer cancelWatch()
+		go func() {
+			for {
+				_ = cache.Watch(watchctx, moby)
+				select {
+				case <-time.After(time.Second):
+				case <-watchctx.Done():
+					return
+				}
+			}
 		}()
-		renderData(term, renderer, namaddrs)
-		ticker := time.NewTicker(20 * time.Millisecond)
-		for {
-			select {
-			case <-ticker.C:
-				renderData(term, renderer, namaddrs)
-			case <-trackingDone:
-				return
+		opts = append(opts,
+			pipeline.WithDockerClient(moby),
+			pipeline.WithDiscoveryOptions(mobynet.WithCache(cache)))
+	}
+
+	for {
+		report, err := digAndRender(ctx, term, renderer, startpointName, opts)
+		if err != nil {
+			return err
+		}
+		// Partial results still get written, but marked as incomplete, so
+		// that they aren't lost.
+		if *jsonOutput != "" {
+			if err := writeJSON(*jsonOutput, report.Names); err != nil {
+				return fmt.Errorf("cannot write results: %w", err)
 			}
 		}
-	}()
-
-	attachedNets, netnsref, err := mobynet.DiscoverAttachedNames(ctx, cln, startpointName)
-	if err != nil {
-		return fmt.Errorf("cannot discover attached networks and their containers: %w", err)
+		if err := reportErrors(report); err != nil || *watch <= 0 {
+			return err
+		}
+		select {
+		case <-time.After(*watch):
+		case <-ctx.Done():
+			return nil
+		}
 	}
+}
 
-	// Now lets put the required processing elements and their plumbing in
-	// place.
-	//
-	//   - Digger producing IP addresses from a list of FQDNs.
-	//   - Verifier consuming the IPs and checking them, producing "verdicts".
-	//   - NamedAddressMap consuming these "verdicts".
-	//
-	// Rendering is done on the information collected by the NamedAddressMap.
-	digger, diggernews, err := dig.New(int(*workerNumber), netnsref)
+// digAndRender runs the pipeline once, rendering its intermediate results and
+// then its final results a last time, so that the terminal never gets left
+// mid-redraw. It returns the final report of the pipeline.
+func digAndRender(
+	ctx context.Context, term *uilive.Writer, renderer *renderer, startpointName string, opts []pipeline.Option,
+) (*pipeline.FinalReport, error) {
+	p, err := pipeline.Run(ctx, startpointName, opts...)
 	if err != nil {
-		return fmt.Errorf("cannot dig address information: %w", err)
+		return nil, err
 	}
-	verifier, news := verifier.New(int(*workerNumber), netnsref)
-	go verifier.Verify(ctx, diggernews)
-	go func() {
-		_ = namaddrs.Track(ctx, news)
-		close(trackingDone)
-	}()
 
-	// Finally feed the information about attached networks and their names into
-	// the Digger, so they can be processed and move through the different
-	// stages. Then close the input stream and wait for all the data to pass the
-	// stages and finally get rendered a last time.
+	reports := make(chan *pipeline.FinalReport)
 	go func() {
-		digger.DigNetworks(context.Background(), attachedNets)
-		digger.StopWait()
+		reports <- p.Report()
 	}()
-	<-renderingDone
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	var report *pipeline.FinalReport
+	for report == nil {
+		select {
+		case <-ticker.C:
+			renderer.SetNetworks(p.Networks())
+			renderData(term, renderer, p.Snapshot())
+		case report = <-reports:
+		}
+	}
+	renderer.SetNetworks(report.Networks)
+	renderer.SetIncomplete(report.Incomplete)
+	renderData(term, renderer, report.Names)
+	return report, nil
+}
+
+// reportErrors returns the errors of the specified final report, that is, why
+// the results are incomplete and any discovery error, or nil.
+func reportErrors(report *pipeline.FinalReport) error {
+	var errs []error
+	if report.Incomplete != "" {
+		errs = append(errs, fmt.Errorf("incomplete results: %s", report.Incomplete))
+	}
+	if report.Err != nil {
+		errs = append(errs, fmt.Errorf("cannot discover attached networks and their containers: %w", report.Err))
+	}
+	return errors.Join(errs...)
+}
 
-	return nil
+// discoveryOptions returns the discovery options filtering networks,
+// containers and names, as specified by the CLI flags. It returns an error in
+// case of malformed glob patterns or regular expressions.
+func discoveryOptions() ([]mobynet.DiscoveryOption, error) {
+	for _, glob := range append(append([]string{}, *networks...), *excludeNetworks...) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid network glob pattern '%s': %w", glob, err)
+		}
+	}
+	opts := []mobynet.DiscoveryOption{
+		mobynet.WithNetworks(*networks...),
+		mobynet.WithoutNetworks(*excludeNetworks...),
+		mobynet.WithContainerLabels(*containerLabels...),
+		mobynet.WithoutContainerLabels(*excludeContainerLabels...),
+		mobynet.WithProjects(*projects...),
+		mobynet.WithoutProjects(*excludeProjects...),
+	}
+	if *names != "" {
+		re, err := regexp.Compile(*names)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --names regular expression: %w", err)
+		}
+		opts = append(opts, mobynet.WithNames(re))
+	}
+	if *excludeNames != "" {
+		re, err := regexp.Compile(*excludeNames)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --exclude-names regular expression: %w", err)
+		}
+		opts = append(opts, mobynet.WithoutNames(re))
+	}
+	return opts, nil
+}
+
+// writeJSON writes the specified named address sets as JSON to the specified
+// file.
+func writeJSON(filename string, sets []dig.NamedAddressSet) error {
+	j, err := json.MarshalIndent(sets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(j, '\n'), 0o644)
 }
 
-// renderData get the current named+verified address data and then renders (and
-// flushes) it to the terminal.
-func renderData(term *uilive.Writer, r *renderer, data *dig.NamedAddressesMap) {
-	r.Render(data.Get())
+// renderData renders (and flushes) the specified named+verified address data to
+// the terminal.
+func renderData(term *uilive.Writer, r *renderer, sets []dig.NamedAddressSet) {
+	r.Render(sets)
 	term.Flush()
 }
@@ -11,18 +11,34 @@ import (
 	"net"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/siemens/mobydig/dig"
 	"github.com/siemens/mobydig/types"
 )
 
+// slowLookupLatency is the lookup latency from which on lookups are rendered as
+// being slow.
+const slowLookupLatency = time.Second
+
+// hostsGroup is the pseudo network name grouping the names from the center's
+// /etc/hosts; it cannot clash with Docker network names, as these never contain
+// slashes.
+const hostsGroup = "/etc/hosts"
+
 // renderer renders the terminal display, based on named+qualified address
 // information passed to its Render method.
 type renderer struct {
 	Indentation int
+	Reverse     bool                // render reverse (PTR) lookup verdicts
+	Externals   map[string]struct{} // external FQDNs to render in their own group
 	centerName  string
 	w           io.Writer
 	spinner     *spinner
+	mu          sync.Mutex          // protects networks and incomplete.
+	networks    []dig.DockerNetwork // attached networks, once discovered.
+	incomplete  string              // why the results are incomplete, if they are.
 }
 
 // newRenderer returns a Render object rendering to the specified io.Writer.
@@ -38,6 +54,24 @@ func newRenderer(w io.Writer, centerName string) *renderer {
 	}
 }
 
+// SetNetworks sets the attached networks, so that the renderer can show their
+// details and explain failed addresses. SetNetworks can be called while
+// rendering.
+func (r *renderer) SetNetworks(nets []dig.DockerNetwork) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.networks = nets
+}
+
+// SetIncomplete marks the results to render as incomplete for the specified
+// reason, such as "interrupted", with "" marking them as complete.
+// SetIncomplete can be called while rendering.
+func (r *renderer) SetIncomplete(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.incomplete = reason
+}
+
 // Stop the renderer's background ticker.
 func (r *renderer) Stop() {
 	r.spinner.Stop()
@@ -45,10 +79,27 @@ func (r *renderer) Stop() {
 
 // Render the given named+qualified addresses.
 func (r *renderer) Render(na []dig.NamedAddressSet) {
-	groups := groupNames(na)
+	r.mu.Lock()
+	nets := r.networks
+	incomplete := r.incomplete
+	r.mu.Unlock()
+	if incomplete != "" {
+		defer fmt.Fprintln(r.w, incompleteStyle.Styled("⚠ incomplete results: "+incomplete))
+	}
+	// Separate the external names from the container and service names, as
+	// they don't belong to any network.
+	var externals, names []dig.NamedAddressSet
+	for _, set := range na {
+		if _, ok := r.Externals[set.FQDN]; ok {
+			externals = append(externals, set)
+			continue
+		}
+		names = append(names, set)
+	}
+	groups := groupNames(names)
 	// If we don't have any name+addressing information yet, show a proxy
 	// message.
-	if len(groups) == 0 {
+	if len(groups) == 0 && len(externals) == 0 {
 		fmt.Fprintf(r.w, "inspecting container %s and its networks...\n", r.centerName)
 		return
 	}
@@ -56,43 +107,73 @@ func (r *renderer) Render(na []dig.NamedAddressSet) {
 	// display, so that the addresses column doesn't zig-zag around across
 	// different groups.
 	maxlen := 0
-	for _, group := range groups {
-		for _, addr := range group {
-			if l := len(addr.FQDN); l > maxlen {
-				maxlen = l
-			}
+	for _, addr := range na {
+		if l := len(addr.FQDN); l > maxlen {
+			maxlen = l
 		}
 	}
 	// Render list of attached networks...
 	fmt.Fprintf(r.w, "networks attached to container %s: ", r.centerName)
-	for idx, group := range groups {
-		if idx == 0 {
-			continue // skip unnamed group
-		}
-		if idx >= 2 {
-			fmt.Fprint(r.w, " ")
+	sep := ""
+	for _, group := range groups {
+		gn := groupName(&group[0])
+		if gn == "" || gn == hostsGroup {
+			continue // skip unnamed and /etc/hosts groups
 		}
-		fmt.Fprint(r.w, networkNameStyle.Styled(groupName(group[0].FQDN)))
+		fmt.Fprint(r.w, sep, networkNameStyle.Styled(gn))
+		sep = " "
 	}
 	fmt.Fprintln(r.w)
+	// Check the reverse lookups, if enabled...
+	var verdicts map[string]dig.ReverseVerdict
+	if r.Reverse {
+		verdicts = dig.ReverseVerdicts(na)
+	}
 	// Render the network groups...
 	for _, group := range groups {
-		gn := groupName(group[0].FQDN)
+		gn := groupName(&group[0])
 		switch gn {
 		case "":
 			fmt.Fprint(r.w, "DNS names for containers/services on any attached network\n")
+		case hostsGroup:
+			fmt.Fprintf(r.w, "Names from /etc/hosts of container %s\n", r.centerName)
 		default:
-			fmt.Fprintf(r.w, "DNS names for containers/services on network %s\n", networkNameStyle.Styled(gn))
+			fmt.Fprintf(r.w, "DNS names for containers/services on network %s", networkNameStyle.Styled(gn))
+			if n := dig.NetworkOf(nets, gn, ""); n != nil {
+				if info := networkInfo(n); info != "" {
+					fmt.Fprint(r.w, networkInfoStyle.Styled(" "+info))
+				}
+			}
+			fmt.Fprintln(r.w)
 		}
 		for _, na := range group {
-			r.renderGroupDetails(maxlen, na)
+			r.renderGroupDetails(maxlen, na, verdicts, nets)
+		}
+	}
+	// Finally render the external names, if any.
+	if len(externals) != 0 {
+		fmt.Fprintf(r.w, "External DNS names resolved via container %s\n", r.centerName)
+		sort.Slice(externals, func(a, b int) bool {
+			return externals[a].FQDN < externals[b].FQDN
+		})
+		for _, na := range externals {
+			sortQualifiedAddresses(na.Addresses)
+			r.renderGroupDetails(maxlen, na, verdicts, nets)
 		}
 	}
 }
 
-// renderGroupDetails renders a network group's labels and qualified addresses.
-func (r *renderer) renderGroupDetails(labelwidth int, na dig.NamedAddressSet) {
+// renderGroupDetails renders a network group's labels and qualified addresses,
+// optionally flagging addresses that don't correctly map back. Addresses are
+// explained in the light of the configuration of their networks, if known.
+func (r *renderer) renderGroupDetails(labelwidth int, na dig.NamedAddressSet, verdicts map[string]dig.ReverseVerdict, nets []dig.DockerNetwork) {
 	fmt.Fprintf(r.w, "%-*s%-*s", r.Indentation, "", labelwidth, strings.TrimSuffix(na.FQDN, "."))
+	// Names from /etc/hosts don't belong to a specific network, so their
+	// addresses need to be explained in terms of the networks they're on.
+	network := groupName(&na)
+	if network == hostsGroup {
+		network = ""
+	}
 	for idx, addr := range na.Addresses {
 		if idx > 0 {
 			fmt.Fprint(r.w, " ")
@@ -104,11 +185,110 @@ func (r *renderer) renderGroupDetails(labelwidth int, na dig.NamedAddressSet) {
 			fmt.Fprint(r.w, verifyingAddressStyle.Styled(" "+r.spinner.Spinner()+addr.Address+" "))
 		case types.Verified:
 			fmt.Fprint(r.w, validAddressStyle.Styled(" ✔ "+addr.Address+" "))
+		case types.Degraded:
+			fmt.Fprint(r.w, degradedAddressStyle.Styled(" ≈ "+addr.Address+" "))
+		case types.Unreachable:
+			fmt.Fprint(r.w, invalidAddressStyle.Styled(" ⊘ "+addr.Address+" "))
 		case types.Invalid:
 			fmt.Fprint(r.w, invalidAddressStyle.Styled(" × "+addr.Address+" "))
 		}
+		// Show the container owning the address, unless it is already the
+		// container name, such as for round-robin service names and aliases.
+		if cntr := addr.Container; cntr != nil {
+			if _, label := groupAndLabel(&na); cntr.Name != label {
+				fmt.Fprint(r.w, containerStyle.Styled("["+cntr.Name+"]"))
+			}
+		}
+		// For ambiguous bare names, show which network each address belongs
+		// to.
+		if na.Has(dig.WarningAmbiguousName) && addr.Network != "" {
+			fmt.Fprint(r.w, ambiguousStyle.Styled("@"+addr.Network))
+		}
+		// Explain misconfigurations and failures to be expected due to the
+		// network driver and options.
+		if expl := dig.Explain(nets, network, addr); expl != nil {
+			if expl.Misconfiguration {
+				fmt.Fprint(r.w, misconfigurationStyle.Styled("(misconfigured: "+expl.Reason+")"))
+			} else {
+				fmt.Fprint(r.w, expectedFailureStyle.Styled("(expected: "+expl.Reason+")"))
+			}
+		}
+		if verdict, ok := verdicts[addr.Address]; ok {
+			switch verdict {
+			case dig.ReverseMissing:
+				fmt.Fprint(r.w, reverseMismatchStyle.Styled("(no PTR)"))
+			case dig.ReverseMismatch:
+				ptrs := make([]string, 0, len(addr.PTRs))
+				for _, ptr := range addr.PTRs {
+					ptrs = append(ptrs, strings.TrimSuffix(ptr, "."))
+				}
+				fmt.Fprint(r.w, reverseMismatchStyle.Styled("(PTR "+strings.Join(ptrs, ",")+")"))
+			}
+		}
+	}
+	// Show how long the lookup took, highlighting slow lookups.
+	if na.Latency != 0 {
+		latency := fmt.Sprintf(" %s", na.Latency.Round(100*time.Microsecond))
+		if na.Latency >= slowLookupLatency {
+			fmt.Fprint(r.w, slowLookupStyle.Styled(latency))
+		} else {
+			fmt.Fprint(r.w, latencyStyle.Styled(latency))
+		}
+	}
+	// Show which networks contributed the addresses of unambiguous bare
+	// names; ambiguous names instead show the networks per address.
+	if networks := na.Networks(); !na.Has(dig.WarningAmbiguousName) && len(networks) == 1 {
+		fmt.Fprint(r.w, networkInfoStyle.Styled(" from "+networks[0]))
+	}
+	// Show the differing addresses DNS would have resolved the name into, if the
+	// name's /etc/hosts entry shadows DNS.
+	if len(na.Shadows) != 0 {
+		fmt.Fprint(r.w, shadowedDNSStyle.Styled(" shadows DNS "+strings.Join(na.Shadows, " ")))
+	}
+	// Show the CNAME chain, if any, that the addresses are attributed to.
+	if len(na.CNAMEs) != 0 {
+		fmt.Fprint(r.w, cnameStyle.Styled(" via"))
+		for _, cname := range na.CNAMEs {
+			fmt.Fprint(r.w, cnameStyle.Styled(" ⇢ "+strings.TrimSuffix(cname, ".")))
+		}
 	}
 	fmt.Fprintln(r.w)
+	// Finally show any warnings, such as about ambiguous bare names, on their
+	// own lines below the name.
+	for _, warning := range na.Warnings {
+		style := ambiguousStyle
+		if warning.Kind == dig.WarningIncomplete {
+			style = incompleteStyle
+		}
+		fmt.Fprintf(r.w, "%-*s%s\n", r.Indentation*2, "", style.Styled("⚠ "+warning.Message))
+	}
+}
+
+// networkInfo returns a short description of the driver, options and subnets
+// of a Docker network, such as "(bridge, internal, IPv6, 172.24.0.0/16 via
+// 172.24.0.1)".
+func networkInfo(n *dig.DockerNetwork) string {
+	info := []string{}
+	if n.Driver != "" {
+		info = append(info, n.Driver)
+	}
+	if n.Internal {
+		info = append(info, "internal")
+	}
+	if n.EnableIPv6 {
+		info = append(info, "IPv6")
+	}
+	for _, subnet := range n.Subnets {
+		if subnet.Gateway != "" {
+			info = append(info, subnet.Subnet+" via "+subnet.Gateway)
+			continue
+		}
+		info = append(info, subnet.Subnet)
+	}
+	if len(info) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(info, ", ") + ")"
 }
 
 // sortQualifiedAddresses sorts a slice of qualified address in place.
@@ -124,32 +304,36 @@ func sortQualifiedAddresses(addrs []types.QualifiedAddressValue) {
 
 // sortFQDNs sorts a slice of named addresses in place according to their
 // grouped labels. That is, sorting order is not lexicographically on the FQDNs,
-// but instead first according to network labels (if not present, then assumed
+// but instead first according to network names (if not present, then assumed
 // to be ""), and second according to the service/container labels.
 func sortFQDNs(addrs []dig.NamedAddressSet) {
 	sort.Slice(addrs, func(a, b int) bool {
-		gA, lA := groupAndLabel(addrs[a].FQDN)
-		gB, lB := groupAndLabel(addrs[b].FQDN)
+		gA, lA := groupAndLabel(&addrs[a])
+		gB, lB := groupAndLabel(&addrs[b])
 		return (gA < gB) || ((gA == gB) && (lA < lB))
 	})
 }
 
-// groupAndLabel returns the group label and the container/service label
-// separately, given an FQDN. If the FQDN consists of only a single label, then
-// this is taken to refer to a container/service label and the group label is
-// assumed to be "".
-func groupAndLabel(fqdn string) (group string, label string) {
-	f := strings.Split(strings.TrimSuffix(fqdn, "."), ".")
-	if len(f) > 1 {
-		return f[1], f[0]
+// groupAndLabel returns the network name and the container/service label
+// separately, given a named address set, based on its network membership. As
+// network and container names might contain dots themselves, we never try to
+// parse them from FQDNs. Instead, names without membership information are
+// assumed to not belong to any network and their label is the FQDN. Names from
+// /etc/hosts are grouped separately.
+func groupAndLabel(set *dig.NamedAddressSet) (group string, label string) {
+	if set.Membership == nil {
+		return "", strings.TrimSuffix(set.FQDN, ".")
+	}
+	if set.Membership.Hosts {
+		return hostsGroup, set.Membership.Label
 	}
-	return "", f[0]
+	return set.Membership.Network, set.Membership.Label
 }
 
-// groupName returns the group label of an FQDN, or "" if the FQDN consists of a
-// single label only.
-func groupName(fqdn string) string {
-	group, _ := groupAndLabel(fqdn)
+// groupName returns the network name of a named address set, or "" if the name
+// doesn't belong to a specific network.
+func groupName(set *dig.NamedAddressSet) string {
+	group, _ := groupAndLabel(set)
 	return group
 }
 
@@ -159,10 +343,10 @@ func groupNames(addrs []dig.NamedAddressSet) [][]dig.NamedAddressSet {
 	groups := [][]dig.NamedAddressSet{}
 	var recentGroup []dig.NamedAddressSet
 	for _, addr := range addrs {
-		gn := groupName(addr.FQDN)
+		gn := groupName(&addr)
 		// if this is the first group ever or we have wandered off into a new
 		// group, then allocate a new group.
-		if recentGroup == nil || gn != groupName(recentGroup[0].FQDN) {
+		if recentGroup == nil || gn != groupName(&recentGroup[0]) {
 			if recentGroup != nil {
 				groups = append(groups, recentGroup)
 			}
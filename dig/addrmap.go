@@ -6,7 +6,9 @@ package dig
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/siemens/mobydig/types"
 )
@@ -14,8 +16,89 @@ import (
 // NamedAddressSet is a DNS FQDN together with a list of associated/resolved
 // qualified network addresses.
 type NamedAddressSet struct {
-	FQDN      string                        `json:"fqdn"`      // the DNS "name"
-	Addresses []types.QualifiedAddressValue `json:"addresses"` // associated IP network address(es)
+	FQDN       string                        `json:"fqdn"`                 // the DNS "name"
+	CNAMEs     []string                      `json:"cnames,omitempty"`     // optional CNAME chain that led to the address(es)
+	Latency    time.Duration                 `json:"latency_ns,omitempty"` // optional total time of the A and AAAA lookups of the name
+	Membership *types.Membership             `json:"membership,omitempty"` // optional Docker network identity of the name
+	Shadows    []string                      `json:"shadows,omitempty"`    // optional addresses DNS resolves the name to, when shadowed by /etc/hosts
+	Addresses  []types.QualifiedAddressValue `json:"addresses"`            // associated IP network address(es), with their TTLs
+	Timeline   []TimelineEntry               `json:"timeline,omitempty"`   // optional timeline of updates
+	Warnings   []Warning                     `json:"warnings,omitempty"`   // optional warnings, such as about ambiguous names
+}
+
+// Networks returns the sorted names of the Docker networks that contributed
+// addresses to this set, as far as known. For bare names, multiple networks
+// indicate that the name is ambiguous across networks.
+func (s *NamedAddressSet) Networks() []string {
+	networks := []string{}
+	seen := map[string]struct{}{}
+	for _, qa := range s.Addresses {
+		if qa.Network == "" {
+			continue
+		}
+		if _, ok := seen[qa.Network]; ok {
+			continue
+		}
+		seen[qa.Network] = struct{}{}
+		networks = append(networks, qa.Network)
+	}
+	sort.Strings(networks)
+	return networks
+}
+
+// TimelineEntry records an update of a name, as accepted by a
+// [NamedAddressesMap]. Updates without provenance information are not
+// recorded.
+type TimelineEntry struct {
+	types.Provenance
+	Address string        `json:"address,omitempty"` // address or "" when announcing the name
+	Quality types.Quality `json:"quality"`           // quality of the address
+}
+
+// ResolutionTime returns the time span from the name first getting announced
+// until the name resolved into its first address. It returns zero if the
+// timeline is incomplete.
+func (s *NamedAddressSet) ResolutionTime() time.Duration {
+	var announced, resolved time.Time
+	for _, entry := range s.Timeline {
+		switch {
+		case entry.Address == "" && announced.IsZero():
+			announced = entry.Time
+		case entry.Address != "" && resolved.IsZero():
+			resolved = entry.Time
+		}
+	}
+	if announced.IsZero() || resolved.IsZero() {
+		return 0
+	}
+	return resolved.Sub(announced)
+}
+
+// VerificationTime returns the time span from the name resolving into its
+// first address until the final verdict on the last of its addresses. It
+// returns zero if the timeline is incomplete or verification still pending.
+func (s *NamedAddressSet) VerificationTime() time.Duration {
+	for _, qa := range s.Addresses {
+		if qa.Quality.IsPending() {
+			return 0
+		}
+	}
+	var resolved, verified time.Time
+	for _, entry := range s.Timeline {
+		if entry.Address == "" {
+			continue
+		}
+		if resolved.IsZero() {
+			resolved = entry.Time
+		}
+		if !entry.Quality.IsPending() && entry.Time.After(verified) {
+			verified = entry.Time
+		}
+	}
+	if resolved.IsZero() || verified.IsZero() {
+		return 0
+	}
+	return verified.Sub(resolved)
 }
 
 // NamedAddressesMap maps DNS FQDNs to their corresponding lists of qualified IP
@@ -24,20 +107,30 @@ type NamedAddressSet struct {
 // names are discovered, resolved into the corresponding IP addresses, and
 // finally (in)validated.
 type NamedAddressesMap struct {
-	m  map[string][]types.QualifiedAddressValue
-	mu sync.Mutex
+	m           map[string]*NamedAddressSet
+	mu          sync.Mutex
+	version     uint64                   // version of the most recent change.
+	subscribers map[*subscriber]struct{} // subscribers to change events.
 }
 
-// Get returns all named addresses from the map.
+// Get returns (a copy of) all named addresses from the map.
 func (m *NamedAddressesMap) Get() []NamedAddressSet {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	return m.get()
+}
+
+// get returns (a copy of) all named addresses from the map, with the caller
+// holding the lock. The returned named address sets come with warnings, as far
+// as applicable.
+func (m *NamedAddressesMap) get() []NamedAddressSet {
 	sets := make([]NamedAddressSet, 0, len(m.m))
-	for name, addrs := range m.m {
-		sets = append(sets, NamedAddressSet{
-			FQDN:      name,
-			Addresses: addrs,
-		})
+	for _, set := range m.m {
+		set := *set
+		set.Addresses = append([]types.QualifiedAddressValue{}, set.Addresses...)
+		set.Timeline = append([]TimelineEntry(nil), set.Timeline...)
+		set.Warnings = set.warnings()
+		sets = append(sets, set)
 	}
 	return sets
 }
@@ -46,15 +139,26 @@ func (m *NamedAddressesMap) Get() []NamedAddressSet {
 // NamedAddressesMap.
 func NewNamedAddressesMap() *NamedAddressesMap {
 	return &NamedAddressesMap{
-		m: map[string][]types.QualifiedAddressValue{},
+		m:           map[string]*NamedAddressSet{},
+		subscribers: map[*subscriber]struct{}{},
 	}
 }
 
 // Update the map with a NamedAddress, augmenting addresses in case they are yet
-// unknown. Known addresses are updated in case they have quality changing as
-// follows:
+// unknown. Known addresses are updated, including their errors, in case their
+// quality changes in a legal transition as follows, see also
+// [types.Transition]:
 //   - from unverified to verifying
-//   - from verifying to either verified or invalid
+//   - from verifying to any of the verdicts invalid, unreachable, degraded or
+//     verified.
+//   - from a verdict to any other verdict.
+//
+// Updates with illegal transitions, such as from verified back to verifying,
+// are stale and thus ignored.
+//
+// The CNAME chain, lookup latency and shadowed DNS addresses of a name are taken
+// from the first address augmenting the name, and its membership from the first
+// update having one.
 func (m *NamedAddressesMap) Update(namaddr types.NamedAddress) {
 	if namaddr == nil {
 		return
@@ -65,28 +169,62 @@ func (m *NamedAddressesMap) Update(namaddr types.NamedAddress) {
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	if qualaddr, ok := m.m[fqdn]; ok {
-		addr := namaddr.Addr()
-		if addr == "" {
-			return
+	set, ok := m.m[fqdn]
+	if !ok {
+		set = &NamedAddressSet{
+			FQDN:       fqdn,
+			Membership: namaddr.NA().Membership,
+			Addresses:  []types.QualifiedAddressValue{},
 		}
-		for idx := range qualaddr {
-			if qualaddr[idx].Address == addr {
-				if namaddr.Qual() > qualaddr[idx].Quality { // slightly simplified "update" rule
-					qualaddr[idx].Quality = namaddr.Qual()
-				}
-				return
-			}
-		}
-		m.m[fqdn] = append(qualaddr, namaddr.QA())
-		return
+		m.m[fqdn] = set
+		m.publish(Event{Kind: NameAdded, FQDN: fqdn})
 	}
 	addr := namaddr.Addr()
 	if addr == "" {
-		m.m[fqdn] = []types.QualifiedAddressValue{}
-	} else {
-		m.m[fqdn] = []types.QualifiedAddressValue{namaddr.QA()}
+		if !ok {
+			set.record(namaddr) // ...the name's announcement
+		}
+		return
+	}
+	for idx := range set.Addresses {
+		if set.Addresses[idx].Address == addr {
+			if namaddr.Qual().Supersedes(set.Addresses[idx].Quality) {
+				set.Addresses[idx] = set.Addresses[idx].WithNewQuality(namaddr.Qual(), namaddr.Err()).QA()
+				set.Addresses[idx].Provenance = namaddr.QA().Provenance
+				set.record(namaddr)
+				qa := set.Addresses[idx]
+				m.publish(Event{Kind: QualityChanged, FQDN: fqdn, Address: &qa})
+			}
+			return
+		}
+	}
+	na := namaddr.NA()
+	if set.Membership == nil {
+		set.Membership = na.Membership
+	}
+	if len(set.Addresses) == 0 {
+		set.CNAMEs = na.CNAMEs
+		set.Latency = na.Latency
+		set.Shadows = na.Shadows
+	}
+	set.Addresses = append(set.Addresses, na.QualifiedAddressValue)
+	set.record(namaddr)
+	qa := na.QualifiedAddressValue
+	m.publish(Event{Kind: AddressAdded, FQDN: fqdn, Address: &qa})
+}
+
+// record an accepted update in the timeline, if the update carries provenance
+// information.
+func (s *NamedAddressSet) record(namaddr types.NamedAddress) {
+	p := namaddr.QA().Provenance
+	if p == nil {
+		return
 	}
+	s.Timeline = append(s.Timeline, TimelineEntry{
+		Provenance: *p,
+		Address:    namaddr.Addr(),
+		Quality:    namaddr.Qual(),
+	})
 }
 
 // Track NamedAddress updates received from the specified update channel until
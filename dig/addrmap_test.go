@@ -0,0 +1,217 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/siemens/mobydig/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("named addresses map", func() {
+
+	It("tracks names, addresses, and qualities", func() {
+		m := NewNamedAddressesMap()
+		m.Update(nil)
+		m.Update(&types.NamedAddressValue{
+			FQDN:       "foo.net_A.",
+			Membership: &types.Membership{Network: "net_A", Label: "foo"},
+		})
+		m.Update(&types.NamedAddressValue{
+			FQDN:                  "foo.net_A.",
+			CNAMEs:                []string{"bar.net_A."},
+			Latency:               42 * time.Millisecond,
+			QualifiedAddressValue: types.QualifiedAddressValue{Address: "172.24.0.2"},
+		})
+		m.Update(&types.NamedAddressValue{
+			FQDN:                  "foo.net_A.",
+			QualifiedAddressValue: types.QualifiedAddressValue{Address: "172.24.0.4"},
+		})
+		m.Update(&types.NamedAddressValue{
+			FQDN:                  "foo.net_A.",
+			QualifiedAddressValue: types.QualifiedAddressValue{Address: "172.24.0.2", Quality: types.Verified},
+		})
+		m.Update(&types.NamedAddressValue{
+			FQDN:                  "foo.net_A.",
+			QualifiedAddressValue: types.QualifiedAddressValue{Address: "172.24.0.2", Quality: types.Verifying},
+		})
+		Expect(m.Get()).To(ConsistOf(And(
+			HaveField("FQDN", "foo.net_A."),
+			HaveField("Membership", HaveValue(Equal(types.Membership{Network: "net_A", Label: "foo"}))),
+			HaveField("CNAMEs", ConsistOf("bar.net_A.")),
+			HaveField("Latency", 42*time.Millisecond),
+			HaveField("Addresses", ConsistOf(
+				HaveField("Quality", types.Verified),
+				HaveField("Quality", types.Unverified),
+			)),
+		)))
+		Expect(json.Marshal(m.Get()[0])).To(ContainSubstring(`"latency_ns":42000000`))
+	})
+
+	It("propagates errors with quality updates", func() {
+		m := NewNamedAddressesMap()
+		m.Update(&types.NamedAddressValue{
+			FQDN:                  "foo.net_A.",
+			QualifiedAddressValue: types.QualifiedAddressValue{Address: "172.24.0.2"},
+		})
+		m.Update(types.NamedAddress(&types.NamedAddressValue{
+			FQDN:                  "foo.net_A.",
+			QualifiedAddressValue: types.QualifiedAddressValue{Address: "172.24.0.2"},
+		}).WithNewQuality(types.Unreachable, errors.New("no replies")).(types.NamedAddress))
+		m.Update(&types.NamedAddressValue{
+			FQDN:                  "foo.net_A.",
+			QualifiedAddressValue: types.QualifiedAddressValue{Address: "172.24.0.2", Quality: types.Verifying},
+		})
+		sets := m.Get()
+		Expect(sets).To(HaveLen(1))
+		Expect(sets[0].Addresses).To(HaveLen(1))
+		Expect(sets[0].Addresses[0].Qual()).To(Equal(types.Unreachable))
+		Expect(sets[0].Addresses[0].Err()).To(MatchError("no replies"))
+	})
+
+	It("records timelines", func() {
+		start := time.Now()
+		at := func(d time.Duration, stage types.Stage) *types.Provenance {
+			return &types.Provenance{Time: start.Add(d), Stage: stage, Origin: "/proc/42/ns/net"}
+		}
+		m := NewNamedAddressesMap()
+		m.Update(&types.NamedAddressValue{
+			FQDN:                  "foo.net_A.",
+			QualifiedAddressValue: types.QualifiedAddressValue{Provenance: at(0, types.StageDigger)},
+		})
+		for _, addr := range []string{"172.24.0.2", "172.24.0.4"} {
+			m.Update(&types.NamedAddressValue{
+				FQDN: "foo.net_A.",
+				QualifiedAddressValue: types.QualifiedAddressValue{
+					Address:    addr,
+					Provenance: at(10*time.Millisecond, types.StageDigger),
+				},
+			})
+		}
+		m.Update(&types.NamedAddressValue{
+			FQDN: "foo.net_A.",
+			QualifiedAddressValue: types.QualifiedAddressValue{
+				Address:    "172.24.0.2",
+				Quality:    types.Verified,
+				Provenance: at(time.Second, types.StagePinger),
+			},
+		})
+		sets := m.Get()
+		Expect(sets).To(HaveLen(1))
+		Expect(sets[0].Timeline).To(HaveLen(4))
+		Expect(sets[0].ResolutionTime()).To(Equal(10 * time.Millisecond))
+		Expect(sets[0].VerificationTime()).To(BeZero())
+
+		m.Update(&types.NamedAddressValue{
+			FQDN: "foo.net_A.",
+			QualifiedAddressValue: types.QualifiedAddressValue{
+				Address:    "172.24.0.4",
+				Quality:    types.Unreachable,
+				Provenance: at(3*time.Second, types.StageVerifier),
+			},
+		})
+		sets = m.Get()
+		Expect(sets[0].Timeline).To(HaveLen(5))
+		Expect(sets[0].Timeline[4]).To(And(
+			HaveField("Stage", types.StageVerifier),
+			HaveField("Address", "172.24.0.4"),
+			HaveField("Quality", types.Unreachable),
+		))
+		Expect(sets[0].VerificationTime()).To(Equal(3*time.Second - 10*time.Millisecond))
+	})
+
+	It("lists the networks contributing addresses", func() {
+		set := NamedAddressSet{
+			FQDN: "foo.",
+			Addresses: []types.QualifiedAddressValue{
+				{Address: "172.25.0.2", Network: "net_B"},
+				{Address: "172.24.0.2", Network: "net_A"},
+				{Address: "172.24.0.3", Network: "net_A"},
+				{Address: "10.0.0.1"},
+			},
+		}
+		Expect(set.Networks()).To(Equal([]string{"net_A", "net_B"}))
+		Expect((&NamedAddressSet{}).Networks()).To(BeEmpty())
+	})
+
+	It("warns about ambiguous bare names", func() {
+		db1 := &types.ContainerInfo{ID: "1", Name: "a-db-1", Project: "a", Service: "db"}
+		db2 := &types.ContainerInfo{ID: "2", Name: "b-db-1", Project: "b", Service: "db"}
+		foo := &types.ContainerInfo{ID: "3", Name: "foo"}
+		bar1 := &types.ContainerInfo{ID: "4", Name: "a-bar-1", Project: "a", Service: "bar"}
+		bar2 := &types.ContainerInfo{ID: "5", Name: "a-bar-2", Project: "a", Service: "bar"}
+		m := NewNamedAddressesMap()
+		update := func(fqdn string, label string, addr string, network string, container *types.ContainerInfo) {
+			m.Update(&types.NamedAddressValue{
+				FQDN:       fqdn,
+				Membership: &types.Membership{Label: label, Networks: []string{"net_A", "net_B", "net_C"}},
+				QualifiedAddressValue: types.QualifiedAddressValue{
+					Address:   addr,
+					Network:   network,
+					Container: container,
+				},
+			})
+		}
+		update("db.", "db", "172.24.0.2", "net_A", db1)
+		update("db.", "db", "172.25.0.2", "net_B", db2)
+		update("foo.", "foo", "172.24.0.3", "net_A", foo)
+		update("foo.", "foo", "172.26.0.3", "net_C", foo)
+		update("bar.", "bar", "172.24.0.4", "net_A", bar1)
+		update("bar.", "bar", "172.24.0.5", "net_A", bar2)
+		update("baz.", "baz", "172.24.0.6", "net_A", nil)
+		update("baz.", "baz", "172.25.0.6", "net_B", nil)
+		m.Update(&types.NamedAddressValue{
+			FQDN:       "foo.net_A.",
+			Membership: &types.Membership{Network: "net_A", Label: "foo"},
+		})
+		Expect(m.Get()).To(ConsistOf(
+			And(HaveField("FQDN", "db."), HaveField("Warnings", ConsistOf(And(
+				HaveField("Kind", WarningAmbiguousName),
+				HaveField("Networks", Equal([]string{"net_A", "net_B"})),
+				HaveField("Message", "bare name db is ambiguous across networks net_A, net_B"),
+			)))),
+			And(HaveField("FQDN", "foo."), HaveField("Warnings", BeEmpty())),
+			And(HaveField("FQDN", "bar."), HaveField("Warnings", BeEmpty())),
+			And(HaveField("FQDN", "baz."), HaveField("Warnings", BeEmpty())),
+			And(HaveField("FQDN", "foo.net_A."), HaveField("Warnings", BeEmpty())),
+		))
+		Expect((&types.Membership{Label: "db", Networks: []string{"net_A", "net_B"}}).Ambiguous()).To(BeTrue())
+		Expect((&types.Membership{Label: "foo", Networks: []string{"net_A"}}).Ambiguous()).To(BeFalse())
+	})
+
+	It("marks incomplete named address sets", func() {
+		sets := []NamedAddressSet{
+			{FQDN: "foo.", Addresses: []types.QualifiedAddressValue{
+				{Address: "172.24.0.2", Quality: types.Verified},
+			}},
+			{FQDN: "bar.", Addresses: []types.QualifiedAddressValue{
+				{Address: "172.24.0.3", Quality: types.Verified},
+				{Address: "172.24.0.4", Quality: types.Unverified},
+			}},
+			{FQDN: "baz.", Addresses: []types.QualifiedAddressValue{}},
+			{FQDN: "qux.", Addresses: []types.QualifiedAddressValue{
+				{Address: "172.24.0.5", Quality: types.Verifying},
+			}},
+		}
+		MarkIncomplete(sets, "interrupted")
+		Expect(sets).To(HaveExactElements(
+			HaveField("Warnings", BeEmpty()),
+			HaveField("Warnings", ConsistOf(And(
+				HaveField("Kind", WarningIncomplete),
+				HaveField("Message", "incomplete: interrupted"),
+			))),
+			HaveField("Warnings", ConsistOf(HaveField("Kind", WarningIncomplete))),
+			HaveField("Warnings", ConsistOf(HaveField("Kind", WarningIncomplete))),
+		))
+		Expect(sets[1].Has(WarningIncomplete)).To(BeTrue())
+		Expect(sets[1].Has(WarningAmbiguousName)).To(BeFalse())
+	})
+
+})
@@ -0,0 +1,162 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/siemens/mobydig/types"
+)
+
+// ChangeKind describes how an address of a name changed between two snapshots
+// of named address sets.
+type ChangeKind int
+
+// The kinds of changes to addresses between snapshots.
+const (
+	AddressAppeared    ChangeKind = iota // address is new to the name.
+	AddressDisappeared                   // address doesn't belong to the name anymore.
+	QualityImproved                      // address quality got better.
+	QualityRegressed                     // address quality got worse.
+)
+
+// String returns the clear-text representation of a ChangeKind value.
+func (k ChangeKind) String() string {
+	switch k {
+	case AddressAppeared:
+		return "appeared"
+	case AddressDisappeared:
+		return "disappeared"
+	case QualityImproved:
+		return "improved"
+	case QualityRegressed:
+		return "regressed"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", k)
+}
+
+// MarshalText returns the clear-text representation of a ChangeKind value, so
+// that changes get marshalled into JSON with their kinds as strings.
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// AddressChange describes a change of an address of a name between two
+// snapshots. For appeared addresses, Old is always [types.Unverified], and for
+// disappeared addresses, New is always [types.Unverified].
+type AddressChange struct {
+	FQDN    string        `json:"fqdn"`    // name the address belongs to
+	Address string        `json:"address"` // the changed address
+	Kind    ChangeKind    `json:"kind"`    // kind of change
+	Old     types.Quality `json:"old"`     // quality in the old snapshot
+	New     types.Quality `json:"new"`     // quality in the new snapshot
+}
+
+// SnapshotDiff lists the differences between two snapshots of named address
+// sets, as returned by [NamedAddressesMap.Get]. Names as well as changes are
+// sorted by name and then by address.
+type SnapshotDiff struct {
+	Appeared    []string        `json:"appeared"`    // names only in the new snapshot
+	Disappeared []string        `json:"disappeared"` // names only in the old snapshot
+	Changes     []AddressChange `json:"changes"`     // changed addresses of names in both snapshots
+}
+
+// Regressions returns only the quality regressions; that is, addresses whose
+// quality got worse.
+func (d *SnapshotDiff) Regressions() []AddressChange {
+	regressions := []AddressChange{}
+	for _, change := range d.Changes {
+		if change.Kind == QualityRegressed {
+			regressions = append(regressions, change)
+		}
+	}
+	return regressions
+}
+
+// Diff returns the differences between an old snapshot of named address sets
+// taken before and a new snapshot taken after. Names are compared as is, so
+// "foo" and "foo." are different names.
+//
+// An address quality improves if the new quality is better than the old
+// quality, see also [types.Quality.Better]; any other change of quality, such
+// as from verified to degraded, counts as a regression. Changes only affecting
+// other information, such as TTLs or errors, are not reported.
+func Diff(before, after []NamedAddressSet) *SnapshotDiff {
+	oldSets := setsByName(before)
+	newSets := setsByName(after)
+	diff := &SnapshotDiff{
+		Appeared:    []string{},
+		Disappeared: []string{},
+		Changes:     []AddressChange{},
+	}
+	for fqdn, oldSet := range oldSets {
+		newSet, ok := newSets[fqdn]
+		if !ok {
+			diff.Disappeared = append(diff.Disappeared, fqdn)
+			continue
+		}
+		diff.Changes = append(diff.Changes, diffAddresses(fqdn, oldSet, newSet)...)
+	}
+	for fqdn := range newSets {
+		if _, ok := oldSets[fqdn]; !ok {
+			diff.Appeared = append(diff.Appeared, fqdn)
+		}
+	}
+	sort.Strings(diff.Appeared)
+	sort.Strings(diff.Disappeared)
+	sort.Slice(diff.Changes, func(a, b int) bool {
+		if diff.Changes[a].FQDN != diff.Changes[b].FQDN {
+			return diff.Changes[a].FQDN < diff.Changes[b].FQDN
+		}
+		return diff.Changes[a].Address < diff.Changes[b].Address
+	})
+	return diff
+}
+
+// setsByName returns the specified named address sets indexed by their names.
+func setsByName(sets []NamedAddressSet) map[string]*NamedAddressSet {
+	m := make(map[string]*NamedAddressSet, len(sets))
+	for idx := range sets {
+		m[sets[idx].FQDN] = &sets[idx]
+	}
+	return m
+}
+
+// diffAddresses returns the changes between the addresses of the same name in
+// an old and a new snapshot.
+func diffAddresses(fqdn string, before, after *NamedAddressSet) []AddressChange {
+	changes := []AddressChange{}
+	oldQuals := map[string]types.Quality{}
+	for _, qa := range before.Addresses {
+		oldQuals[qa.Address] = qa.Quality
+	}
+	newQuals := map[string]types.Quality{}
+	for _, qa := range after.Addresses {
+		newQuals[qa.Address] = qa.Quality
+	}
+	for addr, oldq := range oldQuals {
+		newq, ok := newQuals[addr]
+		switch {
+		case !ok:
+			changes = append(changes, AddressChange{
+				FQDN: fqdn, Address: addr, Kind: AddressDisappeared, Old: oldq, New: types.Unverified})
+		case newq == oldq:
+		case newq.Better(oldq):
+			changes = append(changes, AddressChange{
+				FQDN: fqdn, Address: addr, Kind: QualityImproved, Old: oldq, New: newq})
+		default:
+			changes = append(changes, AddressChange{
+				FQDN: fqdn, Address: addr, Kind: QualityRegressed, Old: oldq, New: newq})
+		}
+	}
+	for addr, newq := range newQuals {
+		if _, ok := oldQuals[addr]; !ok {
+			changes = append(changes, AddressChange{
+				FQDN: fqdn, Address: addr, Kind: AddressAppeared, Old: types.Unverified, New: newq})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,80 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"encoding/json"
+
+	"github.com/siemens/mobydig/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("snapshot diffs", func() {
+
+	set := func(fqdn string, addrquals ...any) NamedAddressSet {
+		s := NamedAddressSet{FQDN: fqdn, Addresses: []types.QualifiedAddressValue{}}
+		for idx := 0; idx < len(addrquals); idx += 2 {
+			s.Addresses = append(s.Addresses, types.QualifiedAddressValue{
+				Address: addrquals[idx].(string),
+				Quality: addrquals[idx+1].(types.Quality),
+			})
+		}
+		return s
+	}
+
+	It("reports no differences", func() {
+		sets := []NamedAddressSet{set("foo.net_A.", "172.24.0.2", types.Verified)}
+		diff := Diff(sets, sets)
+		Expect(diff.Appeared).To(BeEmpty())
+		Expect(diff.Disappeared).To(BeEmpty())
+		Expect(diff.Changes).To(BeEmpty())
+		Expect(diff.Regressions()).To(BeEmpty())
+	})
+
+	It("reports names, addresses, and qualities", func() {
+		before := []NamedAddressSet{
+			set("foo.net_A.", "172.24.0.2", types.Verified, "172.24.0.3", types.Invalid),
+			set("bar.net_A.", "172.24.0.4", types.Verified, "172.24.0.5", types.Verified),
+			set("gone.net_A.", "172.24.0.6", types.Verified),
+		}
+		after := []NamedAddressSet{
+			set("bar.net_A.", "172.24.0.5", types.Verified, "172.24.0.7", types.Degraded),
+			set("foo.net_A.", "172.24.0.2", types.Unreachable, "172.24.0.3", types.Verified),
+			set("new.net_A.", "172.24.0.8", types.Verified),
+		}
+		diff := Diff(before, after)
+		Expect(diff.Appeared).To(ConsistOf("new.net_A."))
+		Expect(diff.Disappeared).To(ConsistOf("gone.net_A."))
+		Expect(diff.Changes).To(HaveExactElements(
+			AddressChange{FQDN: "bar.net_A.", Address: "172.24.0.4", Kind: AddressDisappeared,
+				Old: types.Verified, New: types.Unverified},
+			AddressChange{FQDN: "bar.net_A.", Address: "172.24.0.7", Kind: AddressAppeared,
+				Old: types.Unverified, New: types.Degraded},
+			AddressChange{FQDN: "foo.net_A.", Address: "172.24.0.2", Kind: QualityRegressed,
+				Old: types.Verified, New: types.Unreachable},
+			AddressChange{FQDN: "foo.net_A.", Address: "172.24.0.3", Kind: QualityImproved,
+				Old: types.Invalid, New: types.Verified},
+		))
+		Expect(diff.Regressions()).To(ConsistOf(HaveField("Address", "172.24.0.2")))
+	})
+
+	It("diffs JSON snapshots", func() {
+		j := Successful(json.Marshal([]NamedAddressSet{set("foo.net_A.", "172.24.0.2", types.Verified)}))
+		var before []NamedAddressSet
+		Expect(json.Unmarshal(j, &before)).To(Succeed())
+		diff := Diff(before, []NamedAddressSet{set("foo.net_A.", "172.24.0.2", types.Degraded)})
+		Expect(json.Marshal(diff.Regressions())).To(MatchJSON(`[{
+			"fqdn": "foo.net_A.",
+			"address": "172.24.0.2",
+			"kind": "regressed",
+			"old": "verified",
+			"new": "degraded"
+		}]`))
+	})
+
+})
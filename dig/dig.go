@@ -22,16 +22,26 @@ import (
 type Digger struct {
 	workers *dnsworker.DnsPool
 	news    chan types.NamedAddress
+	reverse bool     // also do reverse (PTR) lookups of the addresses dug.
+	mode    NameMode // which names on Docker networks to dig.
+	origin  string   // network namespace reference the names are dug from.
 }
 
+// DiggerOption can be passed to New when creating new [Digger] objects.
+type DiggerOption func(*Digger)
+
 // New returns a new Digger with a maximum worker pool of the specified size as
 // well as a “news stream”. This news channel sends NamedAddress elements as
 // they are submitted for diggung, as well as the outcome(s) of the digs. Please
 // note that the returned results channel is never closed by a Digger itself.
 //
+// The digger can be configured during creation using the following options:
+//   - [WithReverseLookups]
+//   - [WithNameMode]
+//
 // I dunno what Sir Tim, Mick, Phil, and all the others might think of our
 // digging here...
-func New(size int, netnsref string) (*Digger, chan types.NamedAddress, error) {
+func New(size int, netnsref string, options ...DiggerOption) (*Digger, chan types.NamedAddress, error) {
 	news := make(chan types.NamedAddress, size)
 	dnsclnt := dns.Client{
 		Net: "tcp", // ...since there's some chance that we need more than just two queries
@@ -44,58 +54,184 @@ func New(size int, netnsref string) (*Digger, chan types.NamedAddress, error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	return &Digger{
+	digger := &Digger{
 		workers: workers,
 		news:    news,
-	}, news, nil
+		origin:  netnsref,
+	}
+	for _, opt := range options {
+		opt(digger)
+	}
+	return digger, news, nil
+}
+
+// WithReverseLookups additionally looks up the PTR RRs of each address dug,
+// passing on the names found in the PTRs field of the named addresses. Use
+// [ReverseVerdicts] to check whether the addresses map back to the expected
+// names.
+func WithReverseLookups() DiggerOption {
+	return func(d *Digger) {
+		d.reverse = true
+	}
+}
+
+// WithNameMode sets which names on Docker networks to dig when digging networks:
+// names qualified with their network names, bare names, or both (the default).
+func WithNameMode(mode NameMode) DiggerOption {
+	return func(d *Digger) {
+		d.mode = mode
+	}
 }
 
 // DigNetworks digs the IP addresses visible on a specific set of Docker
 // networks. Intermediate and final results are getting sent to the channel
 // returned beforehand by New.
+//
+// The addresses dug are annotated with the containers they belong to, as far
+// as known from the Endpoints of the networks.
+//
+// Addresses of bare names are annotated with the Docker networks they belong
+// to, so that consumers can tell which networks contributed answers.
+//
+// Names on the default bridge network are legacy links that Docker doesn't
+// serve via its embedded DNS resolver, but instead writes into the center's
+// /etc/hosts. Such names are thus looked up in the HostsFile of the default
+// bridge network instead of being dug via DNS.
+//
+// Use [Digger.DigStream] instead in order to dig networks as they get
+// discovered.
 func (d *Digger) DigNetworks(ctx context.Context, nets []DockerNetwork) {
-	names := AllFQDNsOnAttachedNetworks(nets)
-	d.DigFQDNs(ctx, names)
+	d.digMemberships(ctx, NamesOnAttachedNetworks(nets, d.mode), newNetworkIndex(nets))
+}
+
+// DigMemberships digs the names of the given memberships, passing on the
+// memberships in the Membership field of the named addresses. Intermediate and
+// final results are getting sent to the channel returned beforehand by New.
+func (d *Digger) DigMemberships(ctx context.Context, memberships []types.Membership) {
+	d.digMemberships(ctx, memberships, nil)
+}
+
+// digMemberships digs the names of the given memberships, annotating the
+// addresses dug with their containers from the specified (optional) index.
+func (d *Digger) digMemberships(ctx context.Context, memberships []types.Membership, index *networkIndex) {
+	for idx := range memberships {
+		if !d.dig(ctx, memberships[idx].Name(), &memberships[idx], index) {
+			return
+		}
+	}
 }
 
 // DigFQDNs digs the given list of “host names” (whatever “host names” actually
 // might mean). Intermediate and final results are getting sent to the channel
 // returned beforehand by New.
 func (d *Digger) DigFQDNs(ctx context.Context, names []string) {
-	// Initially sent all unverified FQDNs to get the ball rolling so that the
-	// consumer knows which FQDNs are going to be dug up next. Also submit the
-	// DNS worker jobs to resolve the FQDNs...
 	for _, name := range names {
-		name := dns.Fqdn(name) // TODO: search list???
-		// Initially inform the consumer of any FQDN that will undergo
-		// resolution later; please note that ResolveName will enqueue
-		// resolutions and thus not block. We only block if the consumer doesn't
-		// consume our news ... and then only until the context gets cancelled.
+		if !d.dig(ctx, name, nil, nil) {
+			return
+		}
+	}
+}
+
+// dig the specified name with optional membership, returning false if the
+// context has been cancelled. If a network index is specified, then the
+// addresses dug are annotated with the containers they belong to, as seen on
+// the membership's network, and legacy links get looked up in hosts files.
+func (d *Digger) dig(ctx context.Context, name string, membership *types.Membership, index *networkIndex) bool {
+	// Initially send the unverified FQDN to get the ball rolling so that the
+	// consumer knows which FQDNs are going to be dug up next. Then submit the
+	// DNS worker job to resolve the FQDN...
+	name = dns.Fqdn(name) // TODO: search list???
+	// Initially inform the consumer of any FQDN that will undergo resolution
+	// later; please note that ResolveName will enqueue resolutions and thus not
+	// block. We only block if the consumer doesn't consume our news ... and
+	// then only until the context gets cancelled.
+	select {
+	case d.news <- &types.NamedAddressValue{
+		FQDN:       name,
+		Membership: membership,
+		QualifiedAddressValue: types.QualifiedAddressValue{
+			Provenance: types.NewProvenance(types.StageDigger, d.origin),
+		},
+	}:
+	case <-ctx.Done():
+		return false
+	}
+	if membership != nil && membership.Link {
+		return d.lookupLink(ctx, name, membership, index)
+	}
+	d.workers.Submit(func(conn *dns.Conn) {
+		host, err := dnsworker.Lookup(ctx, conn, name)
+		if err != nil {
+			return
+		}
+		for _, hostaddr := range host.Addrs {
+			addr := hostaddr.Addr
+			var ptrs []string
+			if d.reverse {
+				// Please note that a failed reverse lookup is simply
+				// reported as having no PTRs at all.
+				ptrs, _ = dnsworker.LookupAddr(ctx, conn, addr)
+				if ptrs == nil {
+					ptrs = []string{}
+				}
+			}
+			var cntr *types.ContainerInfo
+			var network string
+			if membership != nil {
+				cntr = index.container(membership.Network, addr)
+				if membership.Network == "" {
+					network = index.network(addr)
+				}
+			}
+			// Avoid blocking enless in case of the context getting
+			// cancelled. The latency is the total time of the A and AAAA
+			// lookups, as the addresses of a name stem from both.
+			select {
+			case d.news <- &types.NamedAddressValue{
+				FQDN:       name,
+				CNAMEs:     hostaddr.CNAMEs,
+				Latency:    host.RTT,
+				Membership: membership,
+				QualifiedAddressValue: types.QualifiedAddressValue{
+					Address:    addr,
+					Quality:    types.Unverified,
+					TTL:        hostaddr.TTL,
+					PTRs:       ptrs,
+					Container:  cntr,
+					Network:    network,
+					Provenance: types.NewProvenance(types.StageDigger, d.origin),
+				},
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	return true
+}
+
+// lookupLink looks up the specified legacy link name in the hosts file of the
+// membership's network, returning false if the context has been cancelled.
+// Names not listed in the hosts file don't resolve, the same as names that
+// cannot be dug via DNS.
+func (d *Digger) lookupLink(ctx context.Context, name string, membership *types.Membership, index *networkIndex) bool {
+	for _, addr := range index.lookup(membership.Network, name) {
 		select {
 		case d.news <- &types.NamedAddressValue{
-			FQDN: name,
+			FQDN:       name,
+			Membership: membership,
+			QualifiedAddressValue: types.QualifiedAddressValue{
+				Address:    addr,
+				Quality:    types.Unverified,
+				Container:  index.container(membership.Network, addr),
+				Provenance: types.NewProvenance(types.StageDigger, d.origin),
+			},
 		}:
 		case <-ctx.Done():
-			return
+			return false
 		}
-		d.workers.ResolveName(ctx, name, func(addrs []string, err error) {
-			for _, addr := range addrs {
-				// Avoid blocking enless in case of the context getting
-				// cancelled.
-				select {
-				case d.news <- &types.NamedAddressValue{
-					FQDN: name,
-					QualifiedAddressValue: types.QualifiedAddressValue{
-						Address: addr,
-						Quality: types.Unverified,
-					},
-				}:
-				case <-ctx.Done():
-					return
-				}
-			}
-		})
 	}
+	return true
 }
 
 // StopWait waits for all queued tasks to get processed and then finally closes
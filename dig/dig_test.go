@@ -151,7 +151,7 @@ var _ = Describe("digging in the (address) dirt", Ordered, func() {
 			return ok
 		}).WithContext(ctx).Should(BeFalse(), "missing signal that digging has finished")
 		Expect(m.m).NotTo(BeEmpty())
-		Expect(m.m).To(HaveEach(HaveEach(HaveField("Quality", Equal(types.Verified)))))
+		Expect(m.m).To(HaveEach(HaveField("Addresses", HaveEach(HaveField("Quality", Equal(types.Verified))))))
 		Expect(vfdone).Should(BeClosed())
 	})
 
@@ -9,6 +9,65 @@ constraints of limited goroutines. That is, the maximum number of each worker
 set is limited for DNS name-to-address resolution, as well as for address
 validation using ICMP pings.
 
+When digging the names on Docker networks, a [Digger] passes on the
+[types.Membership] of each name, that is, the Docker network and the container
+name or alias, so that consumers never need to parse this information from the
+names themselves. Additionally, the addresses dug get annotated with the
+[types.ContainerInfo] of the containers they belong to, so that, for instance,
+unreachable replicas behind round-robin service names can be identified.
+
+A [Digger] created using [WithNameMode] digs only the names qualified with
+their network names, only the bare names, or both. The addresses of bare names
+are annotated with the Docker networks they belong to, so that
+[NamedAddressSet.Networks] tells which networks contributed answers for a bare
+name.
+
+Bare names resolving to different containers or services on multiple networks
+are ambiguous, as Docker's embedded DNS resolver then answers with addresses
+from either network, so that applications might connect to the wrong one. The
+memberships of bare names list the networks their labels appear on, and the
+named address sets returned by [NamedAddressesMap.Get] carry [Warning]s about
+such ambiguous names. The same container attached to multiple networks isn't
+ambiguous.
+
+As Docker's default bridge network lacks an embedded DNS resolver, the legacy
+link names on it (see [types.Membership.Link]) aren't dug via DNS, but instead
+looked up in the center container's /etc/hosts file, the same as the center's
+resolver does. The addresses of link names then get verified as usual.
+
+[Digger.DigHosts] passes on the names from the center's /etc/hosts, such as
+those added using “--add-host” or “extra_hosts”, together with their addresses
+from the hosts file, so that they can be verified the same as names dug from
+DNS. Additionally, these names are dug via DNS in order to detect hosts entries
+shadowing DNS names that resolve into different addresses. As Docker writes
+legacy links into /etc/hosts too, names that are legacy links are skipped, so
+that they are dug only as links.
+
+Instead of handing a [Digger] complete lists of networks or names, and then
+having to call [Digger.StopWait] after the last dig, [Digger.DigStream] digs the
+[Diggable] networks and names received from a channel as they arrive, such as
+while the networks are still being discovered. DigStream digs each name only
+once and closes the news channel after its input channel has been closed and
+all digs have finished, or after its context has been cancelled.
+
+[Explain] explains addresses in the light of the driver and options of the
+Docker network they belong to, such as IPv6 addresses on networks without IPv6
+enabled being misconfigurations, or failures to be expected on internal, macvlan
+and ipvlan networks.
+
+Optionally, a [Digger] created using [WithReverseLookups] also looks up the PTR
+RRs of the addresses dug, so that [ReverseVerdicts] can then check that these
+addresses correctly map back to the names dug.
+
+Instead of repeatedly polling a [NamedAddressesMap] using
+[NamedAddressesMap.Get], consumers can [NamedAddressesMap.Subscribe] to an
+ordered stream of versioned change [Event]s, starting atomically from a
+snapshot of the map.
+
+[Diff] compares two snapshots of named address sets, such as taken before and
+after a deployment, reporting names that appeared or disappeared, as well as
+changed addresses and their quality regressions and improvements.
+
 Digging and pinging is implemented in pure Go, leveraging the incredible Go
 modules [miekg/dns] and [go-ping/ping].
 
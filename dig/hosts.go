@@ -0,0 +1,192 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/siemens/mobydig/dnsworker"
+	"github.com/siemens/mobydig/hostsfile"
+	"github.com/siemens/mobydig/types"
+
+	"github.com/miekg/dns"
+)
+
+// standardHostsNames are the names Docker always writes into the /etc/hosts
+// of containers, regardless of any “--add-host” or “extra_hosts”.
+var standardHostsNames = map[string]struct{}{
+	"localhost":       {},
+	"ip6-localhost":   {},
+	"ip6-loopback":    {},
+	"ip6-localnet":    {},
+	"ip6-mcastprefix": {},
+	"ip6-allnodes":    {},
+	"ip6-allrouters":  {},
+}
+
+// HostsNames returns the names from the specified hosts file that are worth
+// verifying, skipping Docker's standard names as well as names resolving only
+// to loopback or multicast addresses. Additionally, the specified host names of
+// the center container itself are skipped, as Docker writes them into the
+// center's /etc/hosts, too.
+func HostsNames(hosts *hostsfile.Hosts, hostnames ...string) []string {
+	own := map[string]struct{}{}
+	for _, hostname := range hostnames {
+		own[strings.ToLower(hostname)] = struct{}{}
+	}
+	names := []string{}
+	for _, name := range hosts.Names() {
+		if _, ok := standardHostsNames[name]; ok {
+			continue
+		}
+		if _, ok := own[strings.ToLower(name)]; ok {
+			continue
+		}
+		if len(hostsAddrs(hosts, name)) == 0 {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// hostsAddrs returns the addresses of the specified name from the hosts file,
+// skipping loopback and multicast addresses.
+func hostsAddrs(hosts *hostsfile.Hosts, name string) []string {
+	addrs := []string{}
+	for _, addr := range hosts.Lookup(name) {
+		ip := net.ParseIP(addr)
+		if ip == nil || ip.IsLoopback() || ip.IsMulticast() {
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// DigHosts passes on the names from the specified /etc/hosts file of the center
+// container, as returned by [HostsNames] for the specified host names of the
+// center container, together with their addresses from
+// the hosts file. The names are passed on as is, without turning them into
+// FQDNs, and with a [types.Membership] marking them as hosts names. This keeps
+// them separate from the names dug from DNS.
+//
+// Additionally, each name is dug via DNS to check whether its hosts entry
+// shadows a DNS name resolving to different addresses, which are then passed
+// on in the Shadows field of the named addresses.
+//
+// As Docker writes legacy links into the center's /etc/hosts, names that are
+// legacy links on any of the specified networks are skipped, as they get dug
+// as links of the default bridge network instead.
+func (d *Digger) DigHosts(ctx context.Context, hosts *hostsfile.Hosts, hostnames []string, nets ...DockerNetwork) {
+	links := linkNames(nets)
+	for _, name := range HostsNames(hosts, hostnames...) {
+		if _, ok := links[name]; ok {
+			continue
+		}
+		if !d.digHostsName(ctx, name, hostsAddrs(hosts, name)) {
+			return
+		}
+	}
+}
+
+// linkNames returns the legacy link names on the specified networks, that is,
+// the labels on Docker's default bridge network.
+func linkNames(nets []DockerNetwork) map[string]struct{} {
+	links := map[string]struct{}{}
+	for _, net := range nets {
+		if !net.Default {
+			continue
+		}
+		for _, label := range net.Labels {
+			links[label] = struct{}{}
+		}
+	}
+	return links
+}
+
+// digHostsName announces the specified hosts name and then passes on its
+// addresses after digging the name via DNS, returning false if the context has
+// been cancelled.
+func (d *Digger) digHostsName(ctx context.Context, name string, addrs []string) bool {
+	membership := &types.Membership{Label: name, Hosts: true}
+	select {
+	case d.news <- &types.NamedAddressValue{
+		FQDN:       name,
+		Membership: membership,
+		QualifiedAddressValue: types.QualifiedAddressValue{
+			Provenance: types.NewProvenance(types.StageDigger, d.origin),
+		},
+	}:
+	case <-ctx.Done():
+		return false
+	}
+	d.workers.Submit(func(conn *dns.Conn) {
+		// A failed DNS lookup simply means that the hosts entry doesn't shadow
+		// anything.
+		var dnsaddrs []string
+		if host, err := dnsworker.Lookup(ctx, conn, dns.Fqdn(name)); err == nil {
+			for _, hostaddr := range host.Addrs {
+				dnsaddrs = append(dnsaddrs, hostaddr.Addr)
+			}
+		}
+		shadows := Shadows(addrs, dnsaddrs)
+		for _, addr := range addrs {
+			select {
+			case d.news <- &types.NamedAddressValue{
+				FQDN:       name,
+				Membership: membership,
+				Shadows:    shadows,
+				QualifiedAddressValue: types.QualifiedAddressValue{
+					Address:    addr,
+					Quality:    types.Unverified,
+					Provenance: types.NewProvenance(types.StageDigger, d.origin),
+				},
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	return true
+}
+
+// Shadows returns the sorted DNS addresses of a name if they differ from the
+// addresses of its hosts entry, otherwise nil. The order of addresses doesn't
+// matter.
+func Shadows(hostsaddrs []string, dnsaddrs []string) []string {
+	if len(dnsaddrs) == 0 {
+		return nil
+	}
+	hostsSet := map[string]struct{}{}
+	for _, addr := range hostsaddrs {
+		hostsSet[addr] = struct{}{}
+	}
+	dnsSet := map[string]struct{}{}
+	for _, addr := range dnsaddrs {
+		dnsSet[addr] = struct{}{}
+	}
+	if len(hostsSet) == len(dnsSet) {
+		same := true
+		for addr := range dnsSet {
+			if _, ok := hostsSet[addr]; !ok {
+				same = false
+				break
+			}
+		}
+		if same {
+			return nil
+		}
+	}
+	shadows := make([]string, 0, len(dnsSet))
+	for addr := range dnsSet {
+		shadows = append(shadows, addr)
+	}
+	sort.Strings(shadows)
+	return shadows
+}
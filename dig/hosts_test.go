@@ -0,0 +1,50 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"strings"
+
+	"github.com/siemens/mobydig/hostsfile"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("/etc/hosts names", func() {
+
+	It("skips Docker's standard names and the center's own names", func() {
+		hosts := Successful(hostsfile.Parse(strings.NewReader(`127.0.0.1	localhost
+::1	localhost ip6-localhost ip6-loopback
+fe00::0	ip6-localnet
+ff00::0	ip6-mcastprefix
+ff02::1	ip6-allnodes
+ff02::2	ip6-allrouters
+172.24.0.3	0123456789ab
+172.24.0.3	center center.example.com
+127.0.0.1	loopy
+192.168.1.42	registry registry.example.com
+`)))
+		Expect(HostsNames(hosts)).To(Equal([]string{
+			"0123456789ab", "center", "center.example.com", "registry", "registry.example.com"}))
+		Expect(HostsNames(hosts, "Center", "center.example.com", "0123456789ab")).To(Equal([]string{
+			"registry", "registry.example.com"}))
+	})
+
+	DescribeTable("detects DNS names being shadowed",
+		func(hostsaddrs, dnsaddrs, expected []string) {
+			Expect(Shadows(hostsaddrs, dnsaddrs)).To(Equal(expected))
+		},
+		Entry("without DNS addresses", []string{"192.168.1.42"}, nil, nil),
+		Entry("with the same addresses", []string{"192.168.1.42", "192.168.1.43"},
+			[]string{"192.168.1.43", "192.168.1.42"}, nil),
+		Entry("with different addresses", []string{"192.168.1.42"},
+			[]string{"172.24.0.3", "172.24.0.2"}, []string{"172.24.0.2", "172.24.0.3"}),
+		Entry("with only some addresses", []string{"192.168.1.42", "172.24.0.2"},
+			[]string{"172.24.0.2"}, []string{"172.24.0.2"}),
+	)
+
+})
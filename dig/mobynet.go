@@ -4,11 +4,37 @@
 
 package dig
 
+import (
+	"sort"
+
+	"github.com/siemens/mobydig/hostsfile"
+	"github.com/siemens/mobydig/types"
+)
+
 // DockerNetwork describes a single Docker network in terms of its name, as well
 // as the DNS labels of the attached containers and associated service names.
 type DockerNetwork struct {
-	Label  string   `json:"label"`  // name of Docker network used as DNS "TLD" label.
-	Labels []string `json:"labels"` // container and service/alias names used as DNS labels.
+	Label   string   `json:"label"`             // name of Docker network used as DNS "TLD" label.
+	ID      string   `json:"id,omitempty"`      // ID of Docker network, if known.
+	Labels  []string `json:"labels"`            // container and service/alias names used as DNS labels.
+	Aliases []string `json:"aliases,omitempty"` // those Labels that are aliases instead of container names.
+	// containers attached to this network, indexed by their IP addresses on
+	// this network.
+	Endpoints map[string]types.ContainerInfo `json:"endpoints,omitempty"`
+	// true for Docker's default bridge network, which has no embedded DNS;
+	// its Labels then are the legacy link names of the center container.
+	Default bool `json:"default,omitempty"`
+	// path of the center container's hosts file, as seen from the host, to
+	// look up legacy link names of the default bridge network in.
+	HostsFile string `json:"hostsfile,omitempty"`
+	// network driver, such as "bridge", "macvlan", "ipvlan" or "overlay".
+	Driver string `json:"driver,omitempty"`
+	// true for internal networks without any external route.
+	Internal bool `json:"internal,omitempty"`
+	// true if IPv6 is enabled on this network.
+	EnableIPv6 bool `json:"enableipv6,omitempty"`
+	// subnets configured for this network, with their gateways.
+	Subnets []Subnet `json:"subnets,omitempty"`
 }
 
 // AllFQDNsOnAttachedNetworks returns the list of FQDNs that should be
@@ -18,16 +44,189 @@ type DockerNetwork struct {
 // A typical means to get the list of attached networks with labels and aliases
 // might be mobynet.DiscoverAttachedNames.
 func AllFQDNsOnAttachedNetworks(nets []DockerNetwork) []string {
-	names := []string{}
-	flatnames := map[string]struct{}{}
+	memberships := AllNamesOnAttachedNetworks(nets)
+	names := make([]string, 0, len(memberships))
+	for _, membership := range memberships {
+		names = append(names, membership.Name())
+	}
+	return names
+}
+
+// AllNamesOnAttachedNetworks returns the memberships of all names that should
+// be addressable from a particular container, based on the list of attached
+// networks with DNS labels and container names and aliases (also DNS labels).
+// In contrast to [AllFQDNsOnAttachedNetworks], the returned memberships keep
+// the structured identity of the names in terms of network and label.
+//
+// Bare labels are returned only once, even if they appear on multiple
+// networks. A bare label is considered to be an alias only if it is an alias on
+// all networks it appears on. The memberships of bare labels list the networks
+// the labels appear on, so that ambiguous bare labels can be detected, see also
+// [types.Membership.Ambiguous].
+//
+// Labels on the default bridge network are legacy links which are neither
+// qualified with the network name nor resolvable via DNS, so they are returned
+// only as links of the default bridge network.
+func AllNamesOnAttachedNetworks(nets []DockerNetwork) []types.Membership {
+	return NamesOnAttachedNetworks(nets, QualifiedAndBareNames)
+}
+
+// NamesOnAttachedNetworks returns the memberships of the names that should be
+// addressable from a particular container, the same as
+// [AllNamesOnAttachedNetworks], but limited to either the qualified names, the
+// bare names, or both, depending on the specified NameMode. Legacy links are
+// always returned, as they are the only names of their containers.
+func NamesOnAttachedNetworks(nets []DockerNetwork, mode NameMode) []types.Membership {
+	memberships := []types.Membership{}
+	bare := map[string]bool{}             // bare label -> alias?
+	bareNetworks := map[string][]string{} // bare label -> networks
 	for _, net := range nets {
+		aliases := map[string]struct{}{}
+		for _, alias := range net.Aliases {
+			aliases[alias] = struct{}{}
+		}
 		for _, label := range net.Labels {
-			names = append(names, label+"."+net.Label)
-			flatnames[label] = struct{}{}
+			_, isAlias := aliases[label]
+			if net.Default {
+				memberships = append(memberships, types.Membership{
+					Network:   net.Label,
+					NetworkID: net.ID,
+					Label:     label,
+					Alias:     isAlias,
+					Link:      true,
+				})
+				continue
+			}
+			if mode.qualified() {
+				memberships = append(memberships, types.Membership{
+					Network:   net.Label,
+					NetworkID: net.ID,
+					Label:     label,
+					Alias:     isAlias,
+				})
+			}
+			if !mode.bare() {
+				continue
+			}
+			if wasAlias, ok := bare[label]; ok {
+				isAlias = isAlias && wasAlias
+			}
+			bare[label] = isAlias
+			bareNetworks[label] = append(bareNetworks[label], net.Label)
 		}
 	}
-	for flatname := range flatnames {
-		names = append(names, flatname)
+	labels := make([]string, 0, len(bare))
+	for label := range bare {
+		labels = append(labels, label)
 	}
-	return names
+	sort.Strings(labels)
+	for _, label := range labels {
+		networks := bareNetworks[label]
+		sort.Strings(networks)
+		memberships = append(memberships, types.Membership{
+			Label:    label,
+			Alias:    bare[label],
+			Networks: networks,
+		})
+	}
+	return memberships
+}
+
+// endpointIndex maps Docker network names to the containers attached to these
+// networks, indexed by their IP addresses on the networks. The containers on
+// all networks are additionally indexed under the empty network name "", for
+// looking up the containers of bare labels.
+type endpointIndex map[string]map[string]types.ContainerInfo
+
+// newEndpointIndex returns a new endpointIndex for the specified networks.
+func newEndpointIndex(nets []DockerNetwork) endpointIndex {
+	index := endpointIndex{"": {}}
+	for _, net := range nets {
+		endpoints := map[string]types.ContainerInfo{}
+		for addr, cntr := range net.Endpoints {
+			endpoints[addr] = cntr
+			index[""][addr] = cntr
+		}
+		index[net.Label] = endpoints
+	}
+	return index
+}
+
+// container returns the container the specified address belongs to on the
+// specified network (or "" for any network), or nil if unknown.
+func (i endpointIndex) container(network string, addr string) *types.ContainerInfo {
+	cntr, ok := i[network][addr]
+	if !ok {
+		return nil
+	}
+	return &cntr
+}
+
+// networkIndex indexes the containers attached to networks, as well as the
+// hosts files of default bridge networks.
+type networkIndex struct {
+	nets      []DockerNetwork
+	endpoints endpointIndex
+	hosts     map[string]*hostsfile.Hosts // network name -> hosts file
+}
+
+// newNetworkIndex returns a new networkIndex for the specified networks,
+// reading the hosts files of default bridge networks. Unreadable hosts files
+// are skipped, so that the legacy links won't resolve.
+func newNetworkIndex(nets []DockerNetwork) *networkIndex {
+	index := &networkIndex{
+		nets:      nets,
+		endpoints: newEndpointIndex(nets),
+		hosts:     map[string]*hostsfile.Hosts{},
+	}
+	for _, net := range nets {
+		if !net.Default || net.HostsFile == "" {
+			continue
+		}
+		hosts, err := hostsfile.ReadFile(net.HostsFile)
+		if err != nil {
+			continue
+		}
+		index.hosts[net.Label] = hosts
+	}
+	return index
+}
+
+// container returns the container the specified address belongs to on the
+// specified network, or nil if unknown.
+func (i *networkIndex) container(network string, addr string) *types.ContainerInfo {
+	if i == nil {
+		return nil
+	}
+	return i.endpoints.container(network, addr)
+}
+
+// lookup returns the addresses of the specified name as listed in the hosts
+// file of the specified network; it returns nil if there is no hosts file.
+func (i *networkIndex) lookup(network string, name string) []string {
+	if i == nil {
+		return nil
+	}
+	hosts, ok := i.hosts[network]
+	if !ok {
+		return nil
+	}
+	return hosts.Lookup(name)
+}
+
+// network returns the name of the Docker network the specified address belongs
+// to, based on the endpoints and subnets of the networks, or "" if unknown.
+func (i *networkIndex) network(addr string) string {
+	if i == nil {
+		return ""
+	}
+	for _, net := range i.nets {
+		if _, ok := net.Endpoints[addr]; ok {
+			return net.Label
+		}
+	}
+	if net := NetworkOf(i.nets, "", addr); net != nil {
+		return net.Label
+	}
+	return ""
 }
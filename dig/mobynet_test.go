@@ -5,6 +5,11 @@
 package dig
 
 import (
+	"os"
+	"path/filepath"
+
+	"github.com/siemens/mobydig/types"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -30,4 +35,121 @@ var _ = Describe("Docker networks", func() {
 		))
 	})
 
+	It("keeps the network membership of names", func() {
+		cnet := []DockerNetwork{
+			{
+				Label:   "project.default",
+				ID:      "1234",
+				Labels:  []string{"foo", "project.foo.1"},
+				Aliases: []string{"foo"},
+			},
+			{
+				Label:  "net_B",
+				Labels: []string{"foo"},
+			},
+		}
+		Expect(AllNamesOnAttachedNetworks(cnet)).To(ConsistOf(
+			types.Membership{Network: "project.default", NetworkID: "1234", Label: "foo", Alias: true},
+			types.Membership{Network: "project.default", NetworkID: "1234", Label: "project.foo.1"},
+			types.Membership{Network: "net_B", Label: "foo"},
+			types.Membership{Label: "foo", Networks: []string{"net_B", "project.default"}},
+			types.Membership{Label: "project.foo.1", Networks: []string{"project.default"}},
+		))
+	})
+
+	It("limits names to qualified or bare names", func() {
+		cnet := []DockerNetwork{
+			{Label: "net_A", Labels: []string{"foo"}},
+			{Label: "bridge", Labels: []string{"bar"}, Default: true},
+		}
+		Expect(NamesOnAttachedNetworks(cnet, QualifiedNamesOnly)).To(ConsistOf(
+			types.Membership{Network: "net_A", Label: "foo"},
+			types.Membership{Network: "bridge", Label: "bar", Link: true},
+		))
+		Expect(NamesOnAttachedNetworks(cnet, BareNamesOnly)).To(ConsistOf(
+			types.Membership{Label: "foo", Networks: []string{"net_A"}},
+			types.Membership{Network: "bridge", Label: "bar", Link: true},
+		))
+		Expect(NamesOnAttachedNetworks(cnet, QualifiedAndBareNames)).To(HaveLen(3))
+	})
+
+	It("parses name modes", func() {
+		var mode NameMode
+		Expect(mode.UnmarshalText([]byte("bare"))).To(Succeed())
+		Expect(mode).To(Equal(BareNamesOnly))
+		Expect(mode.UnmarshalText([]byte("qualified"))).To(Succeed())
+		Expect(mode).To(Equal(QualifiedNamesOnly))
+		Expect(mode.UnmarshalText([]byte("foobar"))).To(HaveOccurred())
+		Expect(NameMode(42).String()).To(Equal("NameMode(42)"))
+	})
+
+	It("finds the networks of addresses", func() {
+		index := newNetworkIndex([]DockerNetwork{
+			{Label: "net_A", Endpoints: map[string]types.ContainerInfo{"172.24.0.2": {Name: "test-foo-1"}}},
+			{Label: "net_B", Subnets: []Subnet{{Subnet: "172.25.0.0/16"}}},
+		})
+		Expect(index.network("172.24.0.2")).To(Equal("net_A"))
+		Expect(index.network("172.25.0.42")).To(Equal("net_B"))
+		Expect(index.network("10.0.0.1")).To(BeEmpty())
+		Expect((*networkIndex)(nil).network("172.24.0.2")).To(BeEmpty())
+	})
+
+	It("indexes the containers of addresses", func() {
+		foo1 := types.ContainerInfo{ID: "1", Name: "test-foo-1", Project: "test", Service: "foo"}
+		bar1 := types.ContainerInfo{ID: "2", Name: "test-bar-1", Project: "test", Service: "bar"}
+		index := newEndpointIndex([]DockerNetwork{
+			{Label: "net_A", Endpoints: map[string]types.ContainerInfo{"172.24.0.2": foo1}},
+			{Label: "net_B", Endpoints: map[string]types.ContainerInfo{"172.25.0.2": bar1}},
+		})
+		Expect(index.container("net_A", "172.24.0.2")).To(HaveValue(Equal(foo1)))
+		Expect(index.container("net_A", "172.25.0.2")).To(BeNil())
+		Expect(index.container("", "172.25.0.2")).To(HaveValue(Equal(bar1)))
+		Expect(endpointIndex(nil).container("net_A", "172.24.0.2")).To(BeNil())
+	})
+
+	It("keeps legacy links on the default bridge network", func() {
+		cnet := []DockerNetwork{
+			{
+				Label:   "bridge",
+				Labels:  []string{"foo", "test-foo-1"},
+				Aliases: []string{"foo"},
+				Default: true,
+			},
+			{
+				Label:  "net_B",
+				Labels: []string{"bar"},
+			},
+		}
+		Expect(AllNamesOnAttachedNetworks(cnet)).To(ConsistOf(
+			types.Membership{Network: "bridge", Label: "foo", Alias: true, Link: true},
+			types.Membership{Network: "bridge", Label: "test-foo-1", Link: true},
+			types.Membership{Network: "net_B", Label: "bar"},
+			types.Membership{Label: "bar", Networks: []string{"net_B"}},
+		))
+		Expect(AllFQDNsOnAttachedNetworks(cnet)).To(ConsistOf(
+			"foo", "test-foo-1", "bar", "bar.net_B"))
+	})
+
+	It("looks up legacy links in hosts files", func() {
+		hostsFile := filepath.Join(GinkgoT().TempDir(), "hosts")
+		Expect(os.WriteFile(hostsFile, []byte(
+			"127.0.0.1\tlocalhost\n172.17.0.2\tfoo 0123456789ab test-foo-1\n"), 0644)).To(Succeed())
+		foo1 := types.ContainerInfo{ID: "0123456789ab", Name: "test-foo-1"}
+		index := newNetworkIndex([]DockerNetwork{
+			{
+				Label:     "bridge",
+				Labels:    []string{"foo", "test-foo-1"},
+				Endpoints: map[string]types.ContainerInfo{"172.17.0.2": foo1},
+				Default:   true,
+				HostsFile: hostsFile,
+			},
+			{Label: "net_B", HostsFile: hostsFile},
+		})
+		Expect(index.lookup("bridge", "foo.")).To(ConsistOf("172.17.0.2"))
+		Expect(index.lookup("bridge", "bar")).To(BeEmpty())
+		Expect(index.lookup("net_B", "foo")).To(BeNil())
+		Expect(index.container("bridge", "172.17.0.2")).To(HaveValue(Equal(foo1)))
+		Expect((*networkIndex)(nil).lookup("bridge", "foo")).To(BeNil())
+	})
+
 })
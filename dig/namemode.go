@@ -0,0 +1,53 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import "fmt"
+
+// NameMode selects which names of containers and aliases on Docker networks
+// to dig: names qualified with their network names, bare names, or both.
+type NameMode int
+
+// The modes of names to dig.
+const (
+	QualifiedAndBareNames NameMode = iota // dig both qualified and bare names.
+	QualifiedNamesOnly                    // dig only names qualified with their network names.
+	BareNamesOnly                         // dig only bare names, as most applications use.
+)
+
+// String returns the clear-text representation of a NameMode value.
+func (m NameMode) String() string {
+	switch m {
+	case QualifiedAndBareNames:
+		return "both"
+	case QualifiedNamesOnly:
+		return "qualified"
+	case BareNamesOnly:
+		return "bare"
+	}
+	return fmt.Sprintf("NameMode(%d)", m)
+}
+
+// UnmarshalText sets a NameMode value from its clear-text representation.
+func (m *NameMode) UnmarshalText(text []byte) error {
+	for mode := QualifiedAndBareNames; mode <= BareNamesOnly; mode++ {
+		if string(text) == mode.String() {
+			*m = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid NameMode %q", string(text))
+}
+
+// qualified returns true if names qualified with their network names are to
+// be dug.
+func (m NameMode) qualified() bool {
+	return m != BareNamesOnly
+}
+
+// bare returns true if bare names are to be dug.
+func (m NameMode) bare() bool {
+	return m != QualifiedNamesOnly
+}
@@ -0,0 +1,128 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/siemens/mobydig/types"
+)
+
+// Subnet is an IP subnet of a Docker network, in CIDR notation, together with
+// its optional gateway address.
+type Subnet struct {
+	Subnet  string `json:"subnet"`            // subnet in CIDR notation, such as "172.24.0.0/16".
+	Gateway string `json:"gateway,omitempty"` // optional gateway address.
+}
+
+// Contains returns true if the specified address is inside this subnet.
+func (s Subnet) Contains(addr string) bool {
+	_, ipnet, err := net.ParseCIDR(s.Subnet)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(addr)
+	return ip != nil && ipnet.Contains(ip)
+}
+
+// Contains returns true if the specified address is inside one of the subnets
+// of this Docker network.
+func (n *DockerNetwork) Contains(addr string) bool {
+	for _, subnet := range n.Subnets {
+		if subnet.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// NetworkOf returns the Docker network with the specified name or, if name is
+// "", the first Docker network with a subnet containing the specified address.
+// It returns nil if there is no such network.
+func NetworkOf(nets []DockerNetwork, name string, addr string) *DockerNetwork {
+	for idx := range nets {
+		if name != "" {
+			if nets[idx].Label == name {
+				return &nets[idx]
+			}
+			continue
+		}
+		if nets[idx].Contains(addr) {
+			return &nets[idx]
+		}
+	}
+	return nil
+}
+
+// Explanation explains the quality of an address in the light of the
+// configuration of the Docker network the address belongs to.
+type Explanation struct {
+	// true if the address indicates a misconfiguration, regardless of its
+	// quality; otherwise, the failure of an address is to be expected.
+	Misconfiguration bool   `json:"misconfiguration,omitempty"`
+	Reason           string `json:"reason"` // human-readable reason.
+}
+
+// Explain returns an explanation of the specified address on this Docker
+// network, or nil if there is nothing to explain. Addresses indicating
+// misconfigurations are always explained, such as IPv6 addresses on networks
+// without IPv6 enabled. Failed addresses are explained where their failure is
+// to be expected given the network driver and options:
+//   - addresses outside the subnets of internal networks lack an external
+//     route.
+//   - macvlan and ipvlan networks isolate their endpoints from the host, and
+//     reachability between endpoints depends on the parent interface and its
+//     mode.
+func (n *DockerNetwork) Explain(qa types.QualifiedAddressValue) *Explanation {
+	ip := net.ParseIP(qa.Address)
+	if ip == nil {
+		return nil
+	}
+	if ip.To4() == nil && !n.EnableIPv6 {
+		return &Explanation{
+			Misconfiguration: true,
+			Reason:           fmt.Sprintf("IPv6 address on network %s without IPv6 enabled", n.Label),
+		}
+	}
+	if qa.Quality != types.Unreachable && qa.Quality != types.Invalid {
+		return nil
+	}
+	switch {
+	case n.Internal && len(n.Subnets) != 0 && !n.Contains(qa.Address):
+		return &Explanation{
+			Reason: fmt.Sprintf("internal network %s has no external route", n.Label),
+		}
+	case n.Driver == "macvlan" || n.Driver == "ipvlan":
+		return &Explanation{
+			Reason: fmt.Sprintf("%s network %s isolates endpoints depending on its parent interface and mode",
+				n.Driver, n.Label),
+		}
+	}
+	return nil
+}
+
+// Explain returns an explanation of the specified address on the Docker network
+// with the specified name, or nil if there is nothing to explain. If name is "",
+// such as for bare and external names, the Docker network is determined by the
+// address instead, see also [NetworkOf]. If the address doesn't belong to any
+// of the Docker networks, then failures are explained if all networks are
+// internal and thus have no external route.
+func Explain(nets []DockerNetwork, name string, qa types.QualifiedAddressValue) *Explanation {
+	if n := NetworkOf(nets, name, qa.Address); n != nil {
+		return n.Explain(qa)
+	}
+	if len(nets) == 0 || (qa.Quality != types.Unreachable && qa.Quality != types.Invalid) {
+		return nil
+	}
+	for _, n := range nets {
+		if !n.Internal {
+			return nil
+		}
+	}
+	return &Explanation{
+		Reason: "all attached networks are internal without external route",
+	}
+}
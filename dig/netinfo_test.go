@@ -0,0 +1,78 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"github.com/siemens/mobydig/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("network configuration", func() {
+
+	nets := []DockerNetwork{
+		{
+			Label:   "net_A",
+			Driver:  "bridge",
+			Subnets: []Subnet{{Subnet: "172.24.0.0/16", Gateway: "172.24.0.1"}},
+		},
+		{
+			Label:      "net_B",
+			Driver:     "bridge",
+			Internal:   true,
+			EnableIPv6: true,
+			Subnets: []Subnet{
+				{Subnet: "172.25.0.0/16"},
+				{Subnet: "fd00:dead:beef::/64"},
+			},
+		},
+		{
+			Label:   "net_M",
+			Driver:  "macvlan",
+			Subnets: []Subnet{{Subnet: "192.168.1.0/24"}},
+		},
+	}
+
+	It("finds networks by name or address", func() {
+		Expect(NetworkOf(nets, "net_B", "")).To(HaveField("Label", "net_B"))
+		Expect(NetworkOf(nets, "net_C", "172.24.0.2")).To(BeNil())
+		Expect(NetworkOf(nets, "", "172.24.0.2")).To(HaveField("Label", "net_A"))
+		Expect(NetworkOf(nets, "", "fd00:dead:beef::2")).To(HaveField("Label", "net_B"))
+		Expect(NetworkOf(nets, "", "10.0.0.1")).To(BeNil())
+	})
+
+	DescribeTable("explains addresses",
+		func(network string, addr string, q types.Quality, misconfig bool, reason string) {
+			expl := Explain(nets, network, types.QualifiedAddressValue{Address: addr, Quality: q})
+			if reason == "" {
+				Expect(expl).To(BeNil())
+				return
+			}
+			Expect(expl).To(HaveValue(And(
+				HaveField("Misconfiguration", misconfig),
+				HaveField("Reason", ContainSubstring(reason)),
+			)))
+		},
+		Entry("verified address", "net_A", "172.24.0.2", types.Verified, false, ""),
+		Entry("unreachable address on bridge", "net_A", "172.24.0.2", types.Unreachable, false, ""),
+		Entry("IPv6 without IPv6 enabled", "net_A", "fd00::2", types.Verified, true, "without IPv6 enabled"),
+		Entry("IPv6 with IPv6 enabled", "net_B", "fd00:dead:beef::2", types.Unreachable, false, ""),
+		Entry("outside internal network", "net_B", "10.0.0.1", types.Unreachable, false, "no external route"),
+		Entry("unreachable on macvlan", "", "192.168.1.42", types.Unreachable, false, "macvlan network net_M"),
+		Entry("external", "", "10.0.0.1", types.Unreachable, false, ""),
+	)
+
+	It("explains external failures on only internal networks", func() {
+		internals := []DockerNetwork{{Label: "net_B", Internal: true}}
+		Expect(Explain(internals, "", types.QualifiedAddressValue{
+			Address: "10.0.0.1", Quality: types.Unreachable,
+		})).To(HaveField("Reason", ContainSubstring("all attached networks are internal")))
+		Expect(Explain(internals, "", types.QualifiedAddressValue{
+			Address: "10.0.0.1", Quality: types.Verified,
+		})).To(BeNil())
+	})
+
+})
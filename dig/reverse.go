@@ -0,0 +1,79 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// ReverseVerdict indicates whether an address maps back via its PTR RR(s) to a
+// name that in turn resolves to the very same address.
+type ReverseVerdict int
+
+// The reverse lookup verdicts of a network address.
+const (
+	ReverseMissing  ReverseVerdict = iota // address has no PTR RR.
+	ReverseMismatch                       // PTR points to a name not resolving to the address.
+	ReverseMatch                          // PTR points to a name resolving to the address.
+)
+
+// String returns the clear-text representation of a ReverseVerdict value.
+func (v ReverseVerdict) String() string {
+	switch v {
+	case ReverseMissing:
+		return "missing"
+	case ReverseMismatch:
+		return "mismatch"
+	case ReverseMatch:
+		return "match"
+	}
+	return fmt.Sprintf("ReverseVerdict(%d)", v)
+}
+
+// ReverseVerdicts checks the PTR names of the addresses in the specified named
+// address sets, as dug by a [Digger] created using [WithReverseLookups], and
+// returns a map from addresses to their verdicts.
+//
+// An address is considered to correctly map back if at least one of its PTR
+// names is among the dug names and this name resolves to the address. As
+// Docker's embedded DNS resolver answers reverse queries for container
+// addresses with the container names, and mobydig digs all container names,
+// addresses pointing to names that either haven't been dug or that resolve to
+// different addresses are a telltale sign of stale DNS records, such as after
+// recreating containers.
+func ReverseVerdicts(sets []NamedAddressSet) map[string]ReverseVerdict {
+	addrsOfName := map[string]map[string]struct{}{}
+	for _, set := range sets {
+		addrs := map[string]struct{}{}
+		for _, qa := range set.Addresses {
+			addrs[qa.Address] = struct{}{}
+		}
+		addrsOfName[strings.ToLower(dns.Fqdn(set.FQDN))] = addrs
+	}
+	verdicts := map[string]ReverseVerdict{}
+	for _, set := range sets {
+		for _, qa := range set.Addresses {
+			if verdicts[qa.Address] == ReverseMatch {
+				continue
+			}
+			if len(qa.PTRs) == 0 {
+				verdicts[qa.Address] = ReverseMissing
+				continue
+			}
+			verdict := ReverseMismatch
+			for _, ptr := range qa.PTRs {
+				if _, ok := addrsOfName[strings.ToLower(dns.Fqdn(ptr))][qa.Address]; ok {
+					verdict = ReverseMatch
+					break
+				}
+			}
+			verdicts[qa.Address] = verdict
+		}
+	}
+	return verdicts
+}
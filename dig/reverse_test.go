@@ -0,0 +1,47 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"github.com/siemens/mobydig/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("reverse lookups", func() {
+
+	It("checks that addresses map back", func() {
+		sets := []NamedAddressSet{
+			{
+				FQDN: "foo.net_A.",
+				Addresses: []types.QualifiedAddressValue{
+					{Address: "172.24.0.2", PTRs: []string{"test-foo-1.net_A."}},
+					{Address: "172.24.0.4", PTRs: []string{"test-foo-2.net_A."}},
+					{Address: "172.24.0.6"},
+				},
+			},
+			{
+				FQDN: "test-foo-1.net_A.",
+				Addresses: []types.QualifiedAddressValue{
+					{Address: "172.24.0.2", PTRs: []string{"test-foo-1.net_A."}},
+				},
+			},
+			{
+				FQDN: "test-foo-2.net_A.",
+				Addresses: []types.QualifiedAddressValue{
+					{Address: "172.24.0.5", PTRs: []string{"test-foo-2.net_A."}},
+				},
+			},
+		}
+		Expect(ReverseVerdicts(sets)).To(Equal(map[string]ReverseVerdict{
+			"172.24.0.2": ReverseMatch,
+			"172.24.0.4": ReverseMismatch,
+			"172.24.0.5": ReverseMatch,
+			"172.24.0.6": ReverseMissing,
+		}))
+	})
+
+})
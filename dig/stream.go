@@ -0,0 +1,191 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"context"
+
+	"github.com/siemens/mobydig/hostsfile"
+
+	"github.com/miekg/dns"
+)
+
+// Diggable is a single item to be dug by [Digger.DigStream]: either a Docker
+// network with the names on it, a single FQDN, or the names from the center's
+// /etc/hosts file. Exactly one of the fields should be set.
+type Diggable struct {
+	Network   *DockerNetwork   // Docker network to dig the names of.
+	FQDN      string           // single name to dig.
+	Hosts     *hostsfile.Hosts // /etc/hosts of the center container to dig the names of.
+	Hostnames []string         // host names of the center container itself in Hosts, not to be dug.
+}
+
+// NetworkDiggable returns a Diggable for the names on the specified Docker
+// network.
+func NetworkDiggable(net DockerNetwork) Diggable {
+	return Diggable{Network: &net}
+}
+
+// FQDNDiggable returns a Diggable for the specified name.
+func FQDNDiggable(name string) Diggable {
+	return Diggable{FQDN: name}
+}
+
+// HostsDiggable returns a Diggable for the names in the specified /etc/hosts
+// file of the center container, except for the specified host names of the
+// center container itself, see also [Digger.DigHosts].
+func HostsDiggable(hosts *hostsfile.Hosts, hostnames ...string) Diggable {
+	return Diggable{Hosts: hosts, Hostnames: hostnames}
+}
+
+// DigStream digs the networks and names received from the specified input
+// channel as they arrive, such as the networks streamed by
+// mobynet.StreamAttachedNames, so that digging doesn't need to wait for the
+// discovery to complete. Intermediate and final results are getting sent to
+// the channel returned beforehand by New.
+//
+// Each name is dug only once, even if it is received multiple times, such as
+// the same FQDN on its own and as part of a network; the first one received
+// wins. Names qualified with their network names, as well as legacy links, are
+// dug as soon as their network arrives. As the memberships of bare names
+// depend on all networks they appear on, bare names are dug only after the
+// input channel has been closed. The same goes for the names from /etc/hosts:
+// as Docker writes legacy links into the center's /etc/hosts, names that are
+// also legacy links on any of the networks are dug only as links, regardless
+// of the order in which the networks and /etc/hosts arrive.
+//
+// DigStream returns after the input channel has been closed or the context
+// cancelled, and all pending digs have finished. It then closes the news
+// channel, so callers must not call [Digger.StopWait] themselves, nor dig
+// anything else using the same Digger. The producer must always close the
+// input channel; after cancellation, remaining input gets drained and
+// discarded, so that producers never block.
+func (d *Digger) DigStream(ctx context.Context, in <-chan Diggable) {
+	defer d.StopWait()
+	s := &stream{
+		digger: d,
+		dug:    map[string]struct{}{},
+	}
+	for {
+		select {
+		case item, ok := <-in:
+			if !ok {
+				if s.digBareNames(ctx) {
+					s.digHosts(ctx)
+				}
+				return
+			}
+			if !s.dig(ctx, item) {
+				go drain(in)
+				return
+			}
+		case <-ctx.Done():
+			go drain(in)
+			return
+		}
+	}
+}
+
+// drain receives and discards items from the specified channel until it gets
+// closed.
+func drain(in <-chan Diggable) {
+	for range in {
+	}
+}
+
+// stream keeps the state of a single DigStream, that is, the networks received
+// so far and the names already dug.
+type stream struct {
+	digger *Digger
+	nets   []DockerNetwork
+	hosts  []Diggable          // /etc/hosts files to dig after all networks
+	dug    map[string]struct{} // names already dug
+}
+
+// dig the specified item, returning false if the context has been cancelled.
+func (s *stream) dig(ctx context.Context, item Diggable) bool {
+	switch {
+	case item.Network != nil:
+		return s.digNetwork(ctx, *item.Network)
+	case item.Hosts != nil:
+		s.hosts = append(s.hosts, item) // ...gets dug later.
+	case item.FQDN != "":
+		if !s.first(dns.Fqdn(item.FQDN)) {
+			return true
+		}
+		return s.digger.dig(ctx, item.FQDN, nil, nil)
+	}
+	return true
+}
+
+// digNetwork digs the qualified names and legacy links of the specified
+// network, with the addresses dug getting annotated based on all networks
+// received so far.
+func (s *stream) digNetwork(ctx context.Context, net DockerNetwork) bool {
+	// Each network gets its own index snapshot, as the digs of earlier
+	// networks might still be running concurrently.
+	s.nets = append(s.nets, net)
+	index := newNetworkIndex(append([]DockerNetwork{}, s.nets...))
+	memberships := NamesOnAttachedNetworks([]DockerNetwork{net}, s.digger.mode)
+	for idx := range memberships {
+		membership := &memberships[idx]
+		if membership.Network == "" {
+			continue // ...bare names get dug later.
+		}
+		if !s.first(dns.Fqdn(membership.Name())) {
+			continue
+		}
+		if !s.digger.dig(ctx, membership.Name(), membership, index) {
+			return false
+		}
+	}
+	return true
+}
+
+// digBareNames digs the bare names on all networks received, returning false
+// if the context has been cancelled.
+func (s *stream) digBareNames(ctx context.Context) bool {
+	if len(s.nets) == 0 {
+		return true
+	}
+	index := newNetworkIndex(s.nets)
+	memberships := NamesOnAttachedNetworks(s.nets, s.digger.mode)
+	for idx := range memberships {
+		membership := &memberships[idx]
+		if membership.Network != "" || !s.first(dns.Fqdn(membership.Name())) {
+			continue
+		}
+		if !s.digger.dig(ctx, membership.Name(), membership, index) {
+			return false
+		}
+	}
+	return true
+}
+
+// digHosts digs the names from the hosts files received, see also
+// [Digger.DigHosts], skipping the legacy links on all networks received.
+func (s *stream) digHosts(ctx context.Context) {
+	links := linkNames(s.nets)
+	for _, item := range s.hosts {
+		for _, name := range HostsNames(item.Hosts, item.Hostnames...) {
+			if _, ok := links[name]; ok || !s.first(name) {
+				continue
+			}
+			if !s.digger.digHostsName(ctx, name, hostsAddrs(item.Hosts, name)) {
+				return
+			}
+		}
+	}
+}
+
+// first returns true if the specified name hasn't been dug yet, remembering it
+// as being dug from now on.
+func (s *stream) first(name string) bool {
+	if _, ok := s.dug[name]; ok {
+		return false
+	}
+	s.dug[name] = struct{}{}
+	return true
+}
@@ -0,0 +1,228 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/siemens/mobydig/dnsworker"
+	"github.com/siemens/mobydig/hostsfile"
+	"github.com/siemens/mobydig/types"
+
+	"github.com/miekg/dns"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+// newTestDigger returns a Digger talking to a local DNS server that answers
+// all A queries with the same address.
+func newTestDigger(ctx context.Context, opts ...DiggerOption) (*Digger, chan types.NamedAddress) {
+	GinkgoHelper()
+	listener := Successful(net.Listen("tcp", "127.0.0.1:0"))
+	server := &dns.Server{
+		Listener: listener,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			resp := new(dns.Msg)
+			resp.SetReply(req)
+			if req.Question[0].Qtype == dns.TypeA {
+				resp.Answer = append(resp.Answer, &dns.A{
+					Hdr: dns.RR_Header{
+						Name:   req.Question[0].Name,
+						Rrtype: dns.TypeA,
+						Class:  dns.ClassINET,
+						Ttl:    42,
+					},
+					A: net.ParseIP("10.0.0.1"),
+				})
+			}
+			_ = w.WriteMsg(resp)
+		}),
+	}
+	go func() { _ = server.ActivateAndServe() }()
+	DeferCleanup(func() { _ = server.Shutdown() })
+
+	workers := Successful(dnsworker.New(ctx, 2,
+		&dns.Client{Net: "tcp"}, listener.Addr().String()))
+	news := make(chan types.NamedAddress, 2)
+	digger := &Digger{
+		workers: workers,
+		news:    news,
+	}
+	for _, opt := range opts {
+		opt(digger)
+	}
+	return digger, news
+}
+
+var _ = Describe("streamed digging", func() {
+
+	BeforeEach(func() {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(3 * time.Second).ProbeEvery(250 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	It("digs networks and names as they arrive, only once", NodeTimeout(30*time.Second), func(ctx context.Context) {
+		digger, news := newTestDigger(ctx)
+		in := make(chan Diggable)
+		go digger.DigStream(ctx, in)
+
+		By("streaming networks and names")
+		go func() {
+			defer close(in)
+			in <- NetworkDiggable(DockerNetwork{Label: "net_A", Labels: []string{"foo", "bar"}})
+			in <- FQDNDiggable("foo.net_A")
+			in <- NetworkDiggable(DockerNetwork{Label: "net_B", Labels: []string{"foo"}})
+			in <- FQDNDiggable("example.org")
+		}()
+
+		By("collecting the news until the digger is done")
+		announced := map[string]int{}
+		m := NewNamedAddressesMap()
+		Eventually(func() bool {
+			namaddr, ok := <-news
+			if ok {
+				if namaddr.Addr() == "" {
+					announced[namaddr.Name()]++
+				}
+				m.Update(namaddr)
+			}
+			return ok
+		}).WithContext(ctx).Should(BeFalse(), "missing signal that digging has finished")
+
+		Expect(announced).To(Equal(map[string]int{
+			"foo.net_A.":   1,
+			"bar.net_A.":   1,
+			"foo.net_B.":   1,
+			"example.org.": 1,
+			"foo.":         1,
+			"bar.":         1,
+		}))
+		Expect(m.Get()).To(ContainElement(And(
+			HaveField("FQDN", "foo."),
+			HaveField("Membership.Networks", []string{"net_A", "net_B"}),
+			HaveField("Addresses", ConsistOf(HaveField("Address", "10.0.0.1"))),
+		)))
+	})
+
+	When("digging legacy links and /etc/hosts", func() {
+
+		const hostsContents = `127.0.0.1	localhost
+172.17.0.2	0123456789ab
+172.17.0.3	alias test-linked-1
+192.168.1.42	registry
+`
+		var hosts *hostsfile.Hosts
+		var bridge DockerNetwork
+
+		BeforeEach(func() {
+			hostsFile := filepath.Join(GinkgoT().TempDir(), "hosts")
+			Expect(os.WriteFile(hostsFile, []byte(hostsContents), 0o644)).To(Succeed())
+			hosts = Successful(hostsfile.Parse(strings.NewReader(hostsContents)))
+			bridge = DockerNetwork{
+				Label:     "bridge",
+				Labels:    []string{"alias", "test-linked-1"},
+				Aliases:   []string{"alias"},
+				Default:   true,
+				HostsFile: hostsFile,
+			}
+		})
+
+		// collect the news until the digger is done, returning the names
+		// announced together with how often they were announced, as well as
+		// the named address sets.
+		collect := func(ctx context.Context, news <-chan types.NamedAddress) (map[string]int, []NamedAddressSet) {
+			GinkgoHelper()
+			announced := map[string]int{}
+			m := NewNamedAddressesMap()
+			Eventually(func() bool {
+				namaddr, ok := <-news
+				if ok {
+					if namaddr.Addr() == "" {
+						announced[namaddr.Name()]++
+					}
+					m.Update(namaddr)
+				}
+				return ok
+			}).WithContext(ctx).Should(BeFalse(), "missing signal that digging has finished")
+			return announced, m.Get()
+		}
+
+		DescribeTable("digs legacy links only once, regardless of order",
+			func(ctx context.Context, hostsFirst bool) {
+				digger, news := newTestDigger(ctx)
+				in := make(chan Diggable)
+				go digger.DigStream(ctx, in)
+				go func() {
+					defer close(in)
+					if hostsFirst {
+						in <- HostsDiggable(hosts, "0123456789ab")
+						in <- NetworkDiggable(bridge)
+						return
+					}
+					in <- NetworkDiggable(bridge)
+					in <- HostsDiggable(hosts, "0123456789ab")
+				}()
+
+				announced, sets := collect(ctx, news)
+				Expect(announced).To(Equal(map[string]int{
+					"alias.":         1,
+					"test-linked-1.": 1,
+					"registry":       1,
+				}))
+				Expect(sets).To(ContainElement(And(
+					HaveField("FQDN", "alias."),
+					HaveField("Membership.Link", true),
+					HaveField("Addresses", ConsistOf(HaveField("Address", "172.17.0.3"))),
+				)))
+			},
+			Entry("with the network first", NodeTimeout(30*time.Second), false),
+			Entry("with /etc/hosts first", NodeTimeout(30*time.Second), true),
+		)
+
+		It("doesn't dig legacy links and the center's own names as /etc/hosts names", NodeTimeout(30*time.Second), func(ctx context.Context) {
+			digger, news := newTestDigger(ctx)
+			go func() {
+				digger.DigHosts(ctx, hosts, []string{"0123456789ab"}, bridge)
+				digger.StopWait()
+			}()
+			announced, _ := collect(ctx, news)
+			Expect(announced).To(Equal(map[string]int{
+				"registry": 1,
+			}))
+		})
+
+	})
+
+	It("shuts down on cancellation without blocking producers", NodeTimeout(30*time.Second), func(ctx context.Context) {
+		digger, news := newTestDigger(ctx)
+		digctx, cancel := context.WithCancel(ctx)
+		in := make(chan Diggable)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			digger.DigStream(digctx, in)
+		}()
+
+		cancel()
+		Eventually(done).Within(5 * time.Second).Should(BeClosed())
+		Eventually(news).Within(5 * time.Second).Should(BeClosed())
+
+		By("still accepting input after cancellation")
+		in <- FQDNDiggable("foo.example.org")
+		close(in)
+	})
+
+})
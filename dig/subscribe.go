@@ -0,0 +1,130 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/siemens/mobydig/types"
+)
+
+// EventKind describes the kind of change to a [NamedAddressesMap].
+type EventKind int
+
+// The kinds of changes to a NamedAddressesMap.
+const (
+	NameAdded      EventKind = iota // a new name.
+	AddressAdded                    // a new address of a name.
+	QualityChanged                  // a new quality of an address of a name.
+)
+
+// String returns the clear-text representation of an EventKind value.
+func (k EventKind) String() string {
+	switch k {
+	case NameAdded:
+		return "name-added"
+	case AddressAdded:
+		return "address-added"
+	case QualityChanged:
+		return "quality-changed"
+	}
+	return fmt.Sprintf("EventKind(%d)", k)
+}
+
+// MarshalText returns the clear-text representation of an EventKind value, so
+// that events get marshalled into JSON with their kinds as strings.
+func (k EventKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Event describes a single change to a [NamedAddressesMap]. Events carry the
+// version of the map after the change; versions start at 1 and increase by one
+// with each change.
+type Event struct {
+	Version uint64                       `json:"version"`           // map version after this change
+	Kind    EventKind                    `json:"kind"`              // kind of change
+	FQDN    string                       `json:"fqdn"`              // name that changed
+	Address *types.QualifiedAddressValue `json:"address,omitempty"` // added or changed address, nil for new names
+}
+
+// Subscribe atomically returns a snapshot of all named addresses from the map
+// together with the snapshot's version, as well as a channel streaming all
+// later change events in order, starting with version+1. Snapshot and events
+// thus never overlap, nor miss any changes.
+//
+// Slow subscribers never block updating the map, as events are queued per
+// subscriber. The event channel gets closed after the specified context is
+// done, unsubscribing from the map.
+func (m *NamedAddressesMap) Subscribe(ctx context.Context) (sets []NamedAddressSet, version uint64, events <-chan Event) {
+	sub := &subscriber{wakeup: make(chan struct{}, 1)}
+	ch := make(chan Event)
+	m.mu.Lock()
+	sets, version = m.get(), m.version
+	m.subscribers[sub] = struct{}{}
+	m.mu.Unlock()
+	go func() {
+		defer func() {
+			m.mu.Lock()
+			delete(m.subscribers, sub)
+			m.mu.Unlock()
+			close(ch)
+		}()
+		sub.forward(ctx, ch)
+	}()
+	return sets, version, ch
+}
+
+// publish a change event to all subscribers, with the caller holding the lock.
+func (m *NamedAddressesMap) publish(ev Event) {
+	m.version++
+	ev.Version = m.version
+	for sub := range m.subscribers {
+		sub.push(ev)
+	}
+}
+
+// subscriber queues change events for a single subscription until they get
+// forwarded to the subscription's event channel.
+type subscriber struct {
+	mu     sync.Mutex
+	queue  []Event
+	wakeup chan struct{} // signals new events in the queue.
+}
+
+// push a change event into the queue, never blocking.
+func (s *subscriber) push(ev Event) {
+	s.mu.Lock()
+	s.queue = append(s.queue, ev)
+	s.mu.Unlock()
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// forward queued change events to the specified channel until the context is
+// done.
+func (s *subscriber) forward(ctx context.Context, ch chan<- Event) {
+	for {
+		s.mu.Lock()
+		batch := s.queue
+		s.queue = nil
+		s.mu.Unlock()
+		for _, ev := range batch {
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case <-s.wakeup:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"context"
+	"time"
+
+	"github.com/siemens/mobydig/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+)
+
+var _ = Describe("named addresses map subscriptions", func() {
+
+	BeforeEach(func() {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	It("streams versioned changes after an atomic snapshot", func(ctx context.Context) {
+		m := NewNamedAddressesMap()
+		m.Update(&types.NamedAddressValue{
+			FQDN:                  "foo.net_A.",
+			QualifiedAddressValue: types.QualifiedAddressValue{Address: "172.24.0.2"},
+		})
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		sets, version, events := m.Subscribe(ctx)
+		Expect(sets).To(ConsistOf(HaveField("FQDN", "foo.net_A.")))
+		Expect(version).To(Equal(uint64(2)))
+
+		m.Update(&types.NamedAddressValue{
+			FQDN:                  "foo.net_A.",
+			QualifiedAddressValue: types.QualifiedAddressValue{Address: "172.24.0.2", Quality: types.Verifying},
+		})
+		m.Update(&types.NamedAddressValue{FQDN: "bar.net_A."})
+		m.Update(&types.NamedAddressValue{
+			FQDN:                  "bar.net_A.",
+			QualifiedAddressValue: types.QualifiedAddressValue{Address: "172.24.0.4"},
+		})
+		m.Update(&types.NamedAddressValue{ // stale, so no event
+			FQDN:                  "foo.net_A.",
+			QualifiedAddressValue: types.QualifiedAddressValue{Address: "172.24.0.2"},
+		})
+
+		Eventually(events).Should(Receive(And(
+			HaveField("Version", uint64(3)),
+			HaveField("Kind", QualityChanged),
+			HaveField("FQDN", "foo.net_A."),
+			HaveField("Address", HaveField("Quality", types.Verifying)))))
+		Eventually(events).Should(Receive(And(
+			HaveField("Version", uint64(4)),
+			HaveField("Kind", NameAdded),
+			HaveField("FQDN", "bar.net_A."),
+			HaveField("Address", BeNil()))))
+		Eventually(events).Should(Receive(And(
+			HaveField("Version", uint64(5)),
+			HaveField("Kind", AddressAdded),
+			HaveField("Address", HaveField("Address", "172.24.0.4")))))
+		Consistently(events).WithTimeout(100 * time.Millisecond).ShouldNot(Receive())
+
+		cancel()
+		Eventually(events).Should(BeClosed())
+		m.Update(&types.NamedAddressValue{FQDN: "baz.net_A."})
+	})
+
+	It("doesn't block updates on slow subscribers", func(ctx context.Context) {
+		m := NewNamedAddressesMap()
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		_, _, events := m.Subscribe(ctx)
+		for _, fqdn := range []string{"foo.", "bar.", "baz."} {
+			m.Update(&types.NamedAddressValue{FQDN: fqdn})
+		}
+		for _, fqdn := range []string{"foo.", "bar.", "baz."} {
+			Eventually(events).Should(Receive(HaveField("FQDN", fqdn)))
+		}
+	})
+
+})
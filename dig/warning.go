@@ -0,0 +1,127 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/siemens/mobydig/types"
+)
+
+// WarningKind classifies warnings about named address sets.
+type WarningKind string
+
+// The kinds of warnings about named address sets.
+const (
+	// bare name resolves to different containers or services on multiple
+	// networks, so Docker's embedded DNS resolver answers with addresses
+	// from either network.
+	WarningAmbiguousName WarningKind = "ambiguous"
+	// name hasn't been completely dug and verified, as digging was
+	// interrupted, such as by a signal or timeout.
+	WarningIncomplete WarningKind = "incomplete"
+)
+
+// Warning about a named address set, in terms of a warning kind and message.
+type Warning struct {
+	Kind     WarningKind `json:"kind"`               // kind of warning
+	Message  string      `json:"message"`            // warning message
+	Networks []string    `json:"networks,omitempty"` // networks involved, if any
+}
+
+// Has returns true if this named address set comes with a warning of the
+// specified kind.
+func (s *NamedAddressSet) Has(kind WarningKind) bool {
+	for _, warning := range s.Warnings {
+		if warning.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkIncomplete marks those of the specified named address sets as incomplete
+// that either have no addresses yet or still have pending addresses, with
+// the specified reason explaining why digging didn't complete, such as
+// "interrupted" or "timed out". This is meant for marking partial results
+// after digging has been interrupted, such as by a signal or timeout.
+func MarkIncomplete(sets []NamedAddressSet, reason string) {
+	for idx := range sets {
+		set := &sets[idx]
+		if len(set.Addresses) != 0 && !hasPending(set.Addresses) {
+			continue
+		}
+		set.Warnings = append(set.Warnings[:len(set.Warnings):len(set.Warnings)], Warning{
+			Kind:    WarningIncomplete,
+			Message: "incomplete: " + reason,
+		})
+	}
+}
+
+// hasPending returns true if any of the specified addresses is still either
+// unverified or in verification.
+func hasPending(addrs []types.QualifiedAddressValue) bool {
+	for _, addr := range addrs {
+		if addr.Quality.IsPending() {
+			return true
+		}
+	}
+	return false
+}
+
+// warnings returns the warnings about this named address set, or nil if there
+// is nothing to warn about.
+//
+// A bare name is ambiguous only if its addresses belong to different
+// containers or services: the same container attached to multiple networks,
+// or the replicas of the same service, are fine. Addresses without container
+// information cannot tell and thus don't count.
+func (s *NamedAddressSet) warnings() []Warning {
+	m := s.Membership
+	if m == nil || m.Network != "" || m.Hosts {
+		return nil
+	}
+	owners := map[string]struct{}{}
+	networks := map[string]struct{}{}
+	for _, addr := range s.Addresses {
+		owner := ownerOf(addr.Container)
+		if owner == "" {
+			continue
+		}
+		owners[owner] = struct{}{}
+		if addr.Network != "" {
+			networks[addr.Network] = struct{}{}
+		}
+	}
+	if len(owners) < 2 {
+		return nil
+	}
+	names := make([]string, 0, len(networks))
+	for network := range networks {
+		names = append(names, network)
+	}
+	sort.Strings(names)
+	return []Warning{{
+		Kind: WarningAmbiguousName,
+		Message: fmt.Sprintf("bare name %s is ambiguous across networks %s",
+			m.Label, strings.Join(names, ", ")),
+		Networks: names,
+	}}
+}
+
+// ownerOf returns an identification of the service or otherwise container an
+// address belongs to, or "" if unknown.
+func ownerOf(container *types.ContainerInfo) string {
+	switch {
+	case container == nil:
+		return ""
+	case container.Service != "":
+		return "service:" + container.Project + "/" + container.Service
+	default:
+		return "container:" + container.ID
+	}
+}
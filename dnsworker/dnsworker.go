@@ -6,7 +6,6 @@ package dnsworker
 
 import (
 	"context"
-	"fmt"
 	"sync"
 
 	"github.com/gammazero/workerpool"
@@ -112,50 +111,36 @@ func (p *DnsPool) Submit(task func(conn *dns.Conn)) {
 // in-flight as well as scheduled name resolution jobs.
 func (p *DnsPool) ResolveName(ctx context.Context, name string, fn func([]string, error)) {
 	p.Submit(func(conn *dns.Conn) {
-		var addrs []string
-		var err error
-		defer func() { fn(addrs, err) }() // ...ensure triggering the result callback on our way out
+		fn(LookupHost(ctx, conn, name))
+	})
+}
 
-		dnsclnt := dns.Client{}
-		nadanothing := true
-		for _, addrType := range []uint16{dns.TypeA, dns.TypeAAAA} {
-			// don't try to resolve the name if the context has been cancelled;
-			// trigger the callback immediately with the context error.
-			select {
-			case <-ctx.Done():
-				err = ctx.Err()
-				return
-			default:
-			}
+// ResolveHost works like [DnsPool.ResolveName], but passes the resolved IP
+// addresses together with the CNAME chains that led to them to the specified
+// callback function fn.
+//
+// Please note that when the passed context is cancelled this will cancel all
+// in-flight as well as scheduled name resolution jobs.
+func (p *DnsPool) ResolveHost(ctx context.Context, name string, fn func(*Host, error)) {
+	p.Submit(func(conn *dns.Conn) {
+		fn(Lookup(ctx, conn, name))
+	})
+}
 
-			msg := dns.Msg{
-				MsgHdr: dns.MsgHdr{Id: dns.Id()},
-			}
-			name := dns.Fqdn(name)
-			msg.SetQuestion(name, addrType) // TODO: search list???
-			var r *dns.Msg
-			r, _, err = dnsclnt.ExchangeWithConn(&msg, conn)
-			if err != nil {
-				return
-			}
-			for _, rr := range r.Answer {
-				if addrRR, ok := rr.(*dns.A); ok {
-					nadanothing = false
-					addrs = append(addrs, addrRR.A.String())
-					continue
-				}
-				if addrRR, ok := rr.(*dns.AAAA); ok {
-					nadanothing = false
-					addrs = append(addrs, addrRR.AAAA.String())
-				}
-			}
-		}
-		// If we neither got A nor AAAA answers then we consider this to be an
-		// error. This ensures to send an error to the callback together with
-		// the nil list of resolved IP addresses.
-		if nadanothing {
-			err = fmt.Errorf("ResolveName: query for %q yields no answers", name)
-		}
+// Query is a convenience method for submitting a single query for the
+// specified name and RR type, such as [dns.TypeSRV], [dns.TypePTR],
+// [dns.TypeTXT], et cetera. The complete [Response] or an error if the query
+// failed is then passed to the specified callback function fn.
+//
+// In contrast to [DnsPool.ResolveName], Query does not consider an empty answer
+// section to be an error, so callers need to check the response code and
+// sections themselves.
+//
+// Please note that when the passed context is cancelled this will cancel all
+// in-flight as well as scheduled queries.
+func (p *DnsPool) Query(ctx context.Context, name string, qtype uint16, fn func(*Response, error)) {
+	p.Submit(func(conn *dns.Conn) {
+		fn(Exchange(ctx, conn, name, qtype))
 	})
 }
 
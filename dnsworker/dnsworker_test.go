@@ -86,6 +86,59 @@ var _ = Describe("DNS client connection pool", func() {
 		pool.StopWait()
 	})
 
+	It("queries arbitrary RR types", NodeTimeout(30*time.Second), func(ctx context.Context) {
+		dnsclnt := dns.Client{}
+		pool := Successful(New(ctx, 1, &dnsclnt, "8.8.8.8:53"))
+		ch := make(chan *Response)
+
+		pool.Query(ctx,
+			"root-servers.net",
+			dns.TypeNS,
+			func(r *Response, err error) {
+				defer GinkgoRecover()
+				Expect(err).NotTo(HaveOccurred())
+				ch <- r
+				close(ch)
+			})
+		Eventually(ch).Should(Receive(And(
+			HaveField("Name", "root-servers.net."),
+			HaveField("Type", dns.TypeNS),
+			HaveField("Rcode", dns.RcodeSuccess),
+			HaveField("Answer", HaveEach(BeAssignableToTypeOf(&dns.NS{}))),
+			HaveField("RTT", BeNumerically(">", 0)),
+		)))
+		pool.StopWait()
+	})
+
+	It("returns the smallest answer TTL", func() {
+		r := Response{
+			Answer: []dns.RR{
+				&dns.A{Hdr: dns.RR_Header{Ttl: 600}},
+				&dns.A{Hdr: dns.RR_Header{Ttl: 42}},
+				&dns.AAAA{Hdr: dns.RR_Header{Ttl: 666}},
+			},
+		}
+		Expect(r.TTL()).To(Equal(uint32(42)))
+		Expect((&Response{}).TTL()).To(BeZero())
+	})
+
+	It("follows CNAME chains", func() {
+		answer := []dns.RR{
+			&dns.CNAME{Hdr: dns.RR_Header{Name: "lb.example.org."}, Target: "lb-eu.example.net."},
+			&dns.CNAME{Hdr: dns.RR_Header{Name: "Registry.example.org."}, Target: "lb.example.org."},
+			&dns.A{Hdr: dns.RR_Header{Name: "lb-eu.example.net."}},
+		}
+		Expect(cnameChain("registry.example.org.", answer)).To(Equal(
+			[]string{"lb.example.org.", "lb-eu.example.net."}))
+		Expect(cnameChain("foo.example.org.", answer)).To(BeEmpty())
+
+		By("not getting caught in CNAME loops")
+		Expect(cnameChain("a.", []dns.RR{
+			&dns.CNAME{Hdr: dns.RR_Header{Name: "a."}, Target: "b."},
+			&dns.CNAME{Hdr: dns.RR_Header{Name: "b."}, Target: "a."},
+		})).To(Equal([]string{"b.", "a."}))
+	})
+
 	It("reports resolution failures", NodeTimeout(30*time.Second), func(ctx context.Context) {
 		dnsclnt := dns.Client{Net: "udp"}
 		pool := Successful(New(ctx, 1, &dnsclnt, "127.0.0.1:1"))
@@ -3,6 +3,12 @@ Package dnsworker implements a simple limiting DNS client-request execution
 pool. Mobydig uses [DnsPool] with a pool of “DNS workers” for A/AAAA lookups.
 Please note that the A/AAAA queries for a single fqdn are not concurrent.
 
+Besides A/AAAA lookups, [DnsPool.Query] queries any other RR type, such as SRV,
+PTR, TXT, et cetera, passing the complete [Response] with its answer, authority
+and additional sections to a callback. Tasks submitted using [DnsPool.Submit]
+can use [Exchange] in order to issue multiple queries on their assigned DNS
+client connection.
+
 Usage
 
 	dnsclnt := dns.Client{}
@@ -17,6 +23,12 @@ Usage
 	    func(addrs []string, error){
 	        // do something with addrs, unless there's an error reported
 	    })
+	workers.Query(
+	    "_http._tcp.example.org",
+	    dns.TypeSRV,
+	    func(r *dnsworker.Response, error){
+	        // do something with r.Answer, r.TTL(), r.RTT, ...
+	    })
 	workers.Submit(func(conn *dns.Conn){
 	    // do something with the DNS connection
 	})
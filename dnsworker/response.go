@@ -0,0 +1,213 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package dnsworker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Response is the outcome of a single DNS query for a particular name and RR
+// type, consisting of the answer, authority and additional sections, as well as
+// the time it took the DNS server to respond.
+type Response struct {
+	Name       string        // queried name, always fully qualified.
+	Type       uint16        // queried RR type, such as dns.TypeSRV.
+	Rcode      int           // response code, such as dns.RcodeSuccess.
+	Answer     []dns.RR      // answer section RRs.
+	Authority  []dns.RR      // authority section RRs.
+	Additional []dns.RR      // additional section RRs (without any OPT RR).
+	RTT        time.Duration // response time.
+}
+
+// TTL returns the smallest TTL of the RRs in the answer section; that is, the
+// time span the answer as a whole can be considered to be valid. If there are
+// no answer RRs, then TTL returns 0.
+func (r *Response) TTL() uint32 {
+	var ttl uint32
+	for idx, rr := range r.Answer {
+		if t := rr.Header().Ttl; idx == 0 || t < ttl {
+			ttl = t
+		}
+	}
+	return ttl
+}
+
+// Exchange queries the specified name and RR type using the specified DNS
+// client connection and returns the [Response]. Exchange is useful in tasks
+// submitted using [DnsPool.Submit] that need to issue multiple queries on the
+// connection assigned to them.
+//
+// The name is automatically turned into an FQDN. If the passed context has
+// already been cancelled, Exchange immediately returns the context's error
+// without sending any query.
+func Exchange(ctx context.Context, conn *dns.Conn, name string, qtype uint16) (*Response, error) {
+	// don't try to query if the context has been cancelled; return the
+	// context error immediately.
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	name = dns.Fqdn(name)
+	msg := dns.Msg{
+		MsgHdr: dns.MsgHdr{Id: dns.Id()},
+	}
+	msg.SetQuestion(name, qtype)
+	dnsclnt := dns.Client{}
+	r, rtt, err := dnsclnt.ExchangeWithConn(&msg, conn)
+	if err != nil {
+		return nil, err
+	}
+	resp := &Response{
+		Name:      name,
+		Type:      qtype,
+		Rcode:     r.Rcode,
+		Answer:    r.Answer,
+		Authority: r.Ns,
+		RTT:       rtt,
+	}
+	for _, rr := range r.Extra {
+		if _, ok := rr.(*dns.OPT); ok {
+			continue
+		}
+		resp.Additional = append(resp.Additional, rr)
+	}
+	return resp, nil
+}
+
+// Host is the result of looking up the A and AAAA RRs of a name, with the
+// addresses found attributed to the CNAME chains that led to them.
+type Host struct {
+	Name  string        // queried name, always fully qualified.
+	Addrs []HostAddress // IP addresses found.
+	RTT   time.Duration // total response time of the A and AAAA queries.
+}
+
+// HostAddress is an IP address together with the chain of CNAMEs (if any) that
+// led from the queried name to this address, as well as the TTL of the RR.
+type HostAddress struct {
+	Addr   string   // IP address in textual format.
+	CNAMEs []string // CNAME chain in order, not including the queried name itself.
+	TTL    uint32   // TTL of the A/AAAA RR, in seconds.
+}
+
+// CNAMEs returns the (first) CNAME chain of the host, or nil if the queried
+// name resolved without any CNAMEs.
+func (h *Host) CNAMEs() []string {
+	for _, addr := range h.Addrs {
+		if len(addr.CNAMEs) != 0 {
+			return addr.CNAMEs
+		}
+	}
+	return nil
+}
+
+// Lookup queries the A and AAAA RRs of the specified name using the specified
+// DNS client connection and returns the IP addresses found, following any CNAME
+// chain(s) present in the answers. If there are neither A nor AAAA answers,
+// then Lookup returns an error.
+func Lookup(ctx context.Context, conn *dns.Conn, name string) (*Host, error) {
+	host := &Host{Name: dns.Fqdn(name)}
+	for _, addrType := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		r, err := Exchange(ctx, conn, name, addrType) // TODO: search list???
+		if err != nil {
+			return nil, err
+		}
+		host.RTT += r.RTT
+		cnames := cnameChain(host.Name, r.Answer)
+		for _, rr := range r.Answer {
+			if addrRR, ok := rr.(*dns.A); ok {
+				host.Addrs = append(host.Addrs, HostAddress{
+					Addr:   addrRR.A.String(),
+					CNAMEs: cnames,
+					TTL:    addrRR.Hdr.Ttl,
+				})
+				continue
+			}
+			if addrRR, ok := rr.(*dns.AAAA); ok {
+				host.Addrs = append(host.Addrs, HostAddress{
+					Addr:   addrRR.AAAA.String(),
+					CNAMEs: cnames,
+					TTL:    addrRR.Hdr.Ttl,
+				})
+			}
+		}
+	}
+	// If we neither got A nor AAAA answers then we consider this to be an
+	// error.
+	if len(host.Addrs) == 0 {
+		return nil, fmt.Errorf("query for %q yields no answers", name)
+	}
+	return host, nil
+}
+
+// LookupHost queries the A and AAAA RRs of the specified name using the
+// specified DNS client connection and returns the IP addresses in textual
+// format. If there are neither A nor AAAA answers, then LookupHost returns an
+// error.
+func LookupHost(ctx context.Context, conn *dns.Conn, name string) ([]string, error) {
+	host, err := Lookup(ctx, conn, name)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]string, 0, len(host.Addrs))
+	for _, addr := range host.Addrs {
+		addrs = append(addrs, addr.Addr)
+	}
+	return addrs, nil
+}
+
+// cnameChain follows the CNAME RRs in the specified answer section, starting
+// with the specified name, and returns the chain of CNAME targets in order.
+// Resolvers put the CNAME RRs in front of the final A/AAAA RRs, but we don't
+// rely on any specific order here.
+func cnameChain(name string, answer []dns.RR) []string {
+	targets := map[string]string{}
+	for _, rr := range answer {
+		if cnameRR, ok := rr.(*dns.CNAME); ok {
+			targets[strings.ToLower(cnameRR.Hdr.Name)] = cnameRR.Target
+		}
+	}
+	var chain []string
+	// Please note that the chain cannot be longer than the number of CNAME RRs,
+	// so this also safely breaks any CNAME loops.
+	for len(chain) < len(targets) {
+		target, ok := targets[strings.ToLower(name)]
+		if !ok {
+			break
+		}
+		chain = append(chain, target)
+		name = target
+	}
+	return chain
+}
+
+// LookupAddr queries the PTR RRs of the specified IP address (in textual
+// format) using the specified DNS client connection and returns the names the
+// address maps back to. In contrast to [LookupHost], no PTR answers are not
+// considered to be an error, but instead LookupAddr returns an empty list.
+func LookupAddr(ctx context.Context, conn *dns.Conn, addr string) ([]string, error) {
+	arpa, err := dns.ReverseAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Exchange(ctx, conn, arpa, dns.TypePTR)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, rr := range r.Answer {
+		if ptrRR, ok := rr.(*dns.PTR); ok {
+			names = append(names, ptrRR.Ptr)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,16 @@
+/*
+Package hostsfile implements parsing "/etc/hosts" files and looking up names
+in them, following the usual hosts file semantics.
+
+Each non-empty line of a hosts file consists of an IP address, followed by a
+canonical host name and optional aliases, separated by spaces or tabs. Text from
+a "#" to the end of the line is a comment. Looking up a name returns the
+addresses of all lines containing the name, in the order of the lines, with
+names being matched case-insensitively.
+
+Usage
+
+	hosts, err := hostsfile.ReadFile("/proc/42/root/etc/hosts")
+	addrs := hosts.Lookup("foobar")
+*/
+package hostsfile
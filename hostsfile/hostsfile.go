@@ -0,0 +1,104 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package hostsfile
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// Record is a single line of a hosts file, consisting of an IP address and the
+// names associated with it, in the order they appear.
+type Record struct {
+	Addr  string   // IP address in textual format.
+	Names []string // canonical name, followed by aliases.
+}
+
+// Hosts contains the records of a hosts file.
+type Hosts struct {
+	Records []Record // records in the order of the lines.
+}
+
+// ReadFile reads and parses the hosts file with the specified path.
+func ReadFile(path string) (*Hosts, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse the hosts file contents read from the specified reader. Lines with
+// invalid IP addresses or without any names are silently skipped, as the
+// resolver would do. IPv6 addresses with zones are kept with their zones.
+func Parse(r io.Reader) (*Hosts, error) {
+	hosts := &Hosts{Records: []Record{}}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		addr, _, _ := strings.Cut(fields[0], "%")
+		if net.ParseIP(addr) == nil {
+			continue
+		}
+		hosts.Records = append(hosts.Records, Record{
+			Addr:  fields[0],
+			Names: fields[1:],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
+
+// Lookup returns the addresses of the specified name, in the order of the
+// records listing them, without duplicates. Names are matched
+// case-insensitively and any trailing dot is ignored. If the name isn't listed,
+// Lookup returns an empty list.
+func (h *Hosts) Lookup(name string) []string {
+	name = strings.TrimSuffix(name, ".")
+	addrs := []string{}
+	seen := map[string]struct{}{}
+	for _, record := range h.Records {
+		for _, n := range record.Names {
+			if !strings.EqualFold(n, name) {
+				continue
+			}
+			if _, ok := seen[record.Addr]; !ok {
+				seen[record.Addr] = struct{}{}
+				addrs = append(addrs, record.Addr)
+			}
+			break
+		}
+	}
+	return addrs
+}
+
+// Names returns all names listed in the hosts file, in the order they first
+// appear, without duplicates; names differing only in case are considered to
+// be duplicates.
+func (h *Hosts) Names() []string {
+	names := []string{}
+	seen := map[string]struct{}{}
+	for _, record := range h.Records {
+		for _, n := range record.Names {
+			key := strings.ToLower(n)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			names = append(names, n)
+		}
+	}
+	return names
+}
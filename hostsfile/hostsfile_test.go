@@ -0,0 +1,61 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package hostsfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+const etchosts = `127.0.0.1	localhost
+::1	localhost ip6-localhost ip6-loopback
+# a comment line
+172.17.0.3	bar 0123456789ab test-bar-1 # legacy link
+172.17.0.4	Bar
+fe80::1%eth0	linky
+not-an-ip	foo
+172.17.0.5
+172.17.0.2	0123456789ac
+`
+
+var _ = Describe("hosts files", func() {
+
+	It("parses records", func() {
+		hosts := Successful(Parse(strings.NewReader(etchosts)))
+		Expect(hosts.Records).To(HaveExactElements(
+			Record{Addr: "127.0.0.1", Names: []string{"localhost"}},
+			Record{Addr: "::1", Names: []string{"localhost", "ip6-localhost", "ip6-loopback"}},
+			Record{Addr: "172.17.0.3", Names: []string{"bar", "0123456789ab", "test-bar-1"}},
+			Record{Addr: "172.17.0.4", Names: []string{"Bar"}},
+			Record{Addr: "fe80::1%eth0", Names: []string{"linky"}},
+			Record{Addr: "172.17.0.2", Names: []string{"0123456789ac"}},
+		))
+		Expect(hosts.Names()).To(HaveExactElements(
+			"localhost", "ip6-localhost", "ip6-loopback",
+			"bar", "0123456789ab", "test-bar-1",
+			"linky", "0123456789ac"))
+	})
+
+	It("looks up names", func() {
+		hosts := Successful(Parse(strings.NewReader(etchosts)))
+		Expect(hosts.Lookup("localhost")).To(HaveExactElements("127.0.0.1", "::1"))
+		Expect(hosts.Lookup("BAR.")).To(HaveExactElements("172.17.0.3", "172.17.0.4"))
+		Expect(hosts.Lookup("foo")).To(BeEmpty())
+	})
+
+	It("reads files", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "hosts")
+		Expect(os.WriteFile(path, []byte(etchosts), 0o644)).To(Succeed())
+		hosts := Successful(ReadFile(path))
+		Expect(hosts.Lookup("test-bar-1")).To(ConsistOf("172.17.0.3"))
+		Expect(ReadFile(path + "-missing")).Error().To(HaveOccurred())
+	})
+
+})
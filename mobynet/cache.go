@@ -0,0 +1,157 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package mobynet
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/client"
+)
+
+// Cache caches the container and network inspection results of discoveries,
+// so that repeated discoveries, such as in a watch mode, don't need to inspect
+// unchanged containers and networks over and over again. A Cache is safe for
+// concurrent use by multiple discoveries.
+//
+// In order to not serve stale inspection results, a Cache needs to [Cache.Watch]
+// the Docker events, invalidating cached containers and networks as they
+// change.
+type Cache struct {
+	mu         sync.Mutex
+	generation uint64                           // incremented with each invalidation.
+	containers map[string]types.ContainerJSON   // by container name or ID, as inspected.
+	networks   map[string]types.NetworkResource // by network ID.
+}
+
+// NewCache returns a new and empty Cache.
+func NewCache() *Cache {
+	return &Cache{
+		containers: map[string]types.ContainerJSON{},
+		networks:   map[string]types.NetworkResource{},
+	}
+}
+
+// Watch the Docker events of the specified Docker client, invalidating cached
+// containers and networks as they change, until the context is done or the
+// event stream fails. Watch starts by invalidating the whole cache, as it
+// cannot know what changed while not watching. It returns the reason for
+// ending watching.
+func (c *Cache) Watch(ctx context.Context, moby *client.Client) error {
+	evs, errs := moby.Events(ctx, types.EventsOptions{})
+	c.Invalidate()
+	for {
+		select {
+		case ev := <-evs:
+			c.handle(ev)
+		case err := <-errs:
+			// Whatever happened to the event stream, we cannot trust the
+			// cache anymore.
+			c.Invalidate()
+			return err
+		}
+	}
+}
+
+// Invalidate the whole cache.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.containers = map[string]types.ContainerJSON{}
+	c.networks = map[string]types.NetworkResource{}
+}
+
+// handle the specified Docker event, invalidating the affected containers and
+// networks. Frequent container events not changing the container details, such
+// as exec and health status events, are ignored.
+func (c *Cache) handle(ev events.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch ev.Type {
+	case events.ContainerEventType:
+		action := string(ev.Action)
+		if strings.HasPrefix(action, "exec_") || strings.HasPrefix(action, string(events.ActionHealthStatus)) {
+			return
+		}
+		c.generation++
+		c.invalidateContainer(ev.Actor.ID, ev.Actor.Attributes["name"])
+	case events.NetworkEventType:
+		c.generation++
+		delete(c.networks, ev.Actor.ID)
+		// (Dis)connecting containers also changes their network settings.
+		if id := ev.Actor.Attributes["container"]; id != "" {
+			c.invalidateContainer(id, "")
+		}
+	}
+}
+
+// invalidateContainer removes the container with the specified ID or name
+// from the cache, with the caller holding the lock.
+func (c *Cache) invalidateContainer(id string, name string) {
+	delete(c.containers, name)
+	delete(c.containers, id)
+	for key, details := range c.containers {
+		if details.ContainerJSONBase != nil && details.ID == id {
+			delete(c.containers, key)
+		}
+	}
+}
+
+// container returns the cached details of the container with the specified
+// name, if any, as well as the current cache generation.
+func (c *Cache) container(name string) (types.ContainerJSON, bool, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	details, ok := c.containers[name]
+	return copyContainer(details), ok, c.generation
+}
+
+// putContainer caches the specified container details under the specified
+// name, unless the cache has been invalidated since the specified generation,
+// as the details then might already be stale.
+func (c *Cache) putContainer(generation uint64, name string, details types.ContainerJSON) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.containers[name] = copyContainer(details)
+}
+
+// copyContainer returns a copy of the specified container details that can be
+// modified without affecting the original details, as far as discovery is
+// concerned: as the ContainerJSONBase is embedded by pointer, the container
+// name would otherwise be shared.
+func copyContainer(details types.ContainerJSON) types.ContainerJSON {
+	if details.ContainerJSONBase != nil {
+		base := *details.ContainerJSONBase
+		details.ContainerJSONBase = &base
+	}
+	return details
+}
+
+// network returns the cached details of the network with the specified ID, if
+// any, as well as the current cache generation.
+func (c *Cache) network(id string) (types.NetworkResource, bool, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	details, ok := c.networks[id]
+	return details, ok, c.generation
+}
+
+// putNetwork caches the specified network details, unless the cache has been
+// invalidated since the specified generation.
+func (c *Cache) putNetwork(generation uint64, details types.NetworkResource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.networks[details.ID] = details
+}
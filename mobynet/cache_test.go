@@ -0,0 +1,101 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package mobynet
+
+import (
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("discovery cache", func() {
+
+	cntr := func(id, name string) types.ContainerJSON {
+		return types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{ID: id, Name: "/" + name},
+		}
+	}
+
+	It("caches copies of containers", func() {
+		c := NewCache()
+		_, ok, gen := c.container("test-foo-1")
+		Expect(ok).To(BeFalse())
+		c.putContainer(gen, "test-foo-1", cntr("1234", "test-foo-1"))
+		details, ok, _ := c.container("test-foo-1")
+		Expect(ok).To(BeTrue())
+		details.Name = "foobar"
+		details, _, _ = c.container("test-foo-1")
+		Expect(details.Name).To(Equal("/test-foo-1"))
+	})
+
+	It("doesn't cache stale inspection results", func() {
+		c := NewCache()
+		_, _, gen := c.container("test-foo-1")
+		c.handle(events.Message{
+			Type:   events.ContainerEventType,
+			Action: events.ActionDie,
+			Actor:  events.Actor{ID: "1234"},
+		})
+		c.putContainer(gen, "test-foo-1", cntr("1234", "test-foo-1"))
+		_, ok, _ := c.container("test-foo-1")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("invalidates containers and networks on events", func() {
+		c := NewCache()
+		_, _, gen := c.container("")
+		c.putContainer(gen, "test-foo-1", cntr("1234", "test-foo-1"))
+		c.putContainer(gen, "5678", cntr("5678", "test-bar-1"))
+		c.putContainer(gen, "test-baz-1", cntr("9abc", "test-baz-1"))
+		c.putNetwork(gen, types.NetworkResource{ID: "net1"})
+		c.putNetwork(gen, types.NetworkResource{ID: "net2"})
+
+		By("ignoring exec and health events")
+		c.handle(events.Message{
+			Type:   events.ContainerEventType,
+			Action: events.ActionExecStart + ": /bin/sh",
+			Actor:  events.Actor{ID: "1234"},
+		})
+		c.handle(events.Message{
+			Type:   events.ContainerEventType,
+			Action: events.ActionHealthStatusHealthy,
+			Actor:  events.Actor{ID: "1234"},
+		})
+		_, ok, _ := c.container("test-foo-1")
+		Expect(ok).To(BeTrue())
+
+		By("invalidating containers by ID")
+		c.handle(events.Message{
+			Type:   events.ContainerEventType,
+			Action: events.ActionRename,
+			Actor:  events.Actor{ID: "1234", Attributes: map[string]string{"name": "test-fool-1"}},
+		})
+		_, ok, _ = c.container("test-foo-1")
+		Expect(ok).To(BeFalse())
+
+		By("invalidating networks and their (dis)connected containers")
+		c.handle(events.Message{
+			Type:   events.NetworkEventType,
+			Action: events.ActionDisconnect,
+			Actor:  events.Actor{ID: "net1", Attributes: map[string]string{"container": "5678"}},
+		})
+		_, ok, _ = c.network("net1")
+		Expect(ok).To(BeFalse())
+		_, ok, _ = c.network("net2")
+		Expect(ok).To(BeTrue())
+		_, ok, _ = c.container("5678")
+		Expect(ok).To(BeFalse())
+		_, ok, _ = c.container("test-baz-1")
+		Expect(ok).To(BeTrue())
+
+		By("invalidating everything")
+		c.Invalidate()
+		_, ok, _ = c.container("test-baz-1")
+		Expect(ok).To(BeFalse())
+	})
+
+})
@@ -0,0 +1,126 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package mobynet
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// DefaultConcurrency is the default maximum number of concurrent container and
+// network inspections during a discovery.
+const DefaultConcurrency = 8
+
+// DiscoveryOption can be passed to DiscoverAttachedNames and
+// StreamAttachedNames in order to filter the networks, containers and names to
+// discover, as well as to tune the discovery.
+type DiscoveryOption func(*discovery)
+
+// discovery is the state of a single discovery, consisting of the filter and
+// the concurrently running inspections sharing a cache.
+type discovery struct {
+	filter
+	moby        *client.Client
+	concurrency int           // maximum number of concurrent inspections.
+	inflight    chan struct{} // limits concurrent inspections.
+	cache       *Cache
+}
+
+// WithConcurrency limits the number of concurrent container and network
+// inspections to the specified number, defaulting to [DefaultConcurrency].
+func WithConcurrency(n int) DiscoveryOption {
+	return func(d *discovery) {
+		d.concurrency = n
+	}
+}
+
+// WithCache shares the specified cache of inspection results across
+// discoveries, such as the repeated discoveries of a watch mode. In order to
+// not serve stale inspection results, the cache must [Cache.Watch] the Docker
+// events.
+func WithCache(cache *Cache) DiscoveryOption {
+	return func(d *discovery) {
+		d.cache = cache
+	}
+}
+
+// newDiscovery returns a new discovery using the specified Docker client,
+// configured by the specified options.
+func newDiscovery(moby *client.Client, opts ...DiscoveryOption) *discovery {
+	d := &discovery{
+		moby:        moby,
+		concurrency: DefaultConcurrency,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.concurrency < 1 {
+		d.concurrency = 1
+	}
+	d.inflight = make(chan struct{}, d.concurrency)
+	if d.cache == nil {
+		// Without a shared cache, we still avoid repeated inspection of
+		// containers that might be connected to multiple networks the center
+		// container is also attached to.
+		d.cache = NewCache()
+	}
+	return d
+}
+
+// acquire an inspection slot, waiting for a slot to become free or the context
+// to be done.
+func (d *discovery) acquire(ctx context.Context) error {
+	select {
+	case d.inflight <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release an inspection slot.
+func (d *discovery) release() {
+	<-d.inflight
+}
+
+// inspectContainer returns the details of the container with the specified
+// name or ID, preferably from the cache.
+func (d *discovery) inspectContainer(ctx context.Context, nameOrID string) (types.ContainerJSON, error) {
+	details, ok, generation := d.cache.container(nameOrID)
+	if ok {
+		return details, nil
+	}
+	if err := d.acquire(ctx); err != nil {
+		return types.ContainerJSON{}, err
+	}
+	defer d.release()
+	details, err := d.moby.ContainerInspect(ctx, nameOrID)
+	if err != nil {
+		return details, err
+	}
+	d.cache.putContainer(generation, nameOrID, details)
+	return details, nil
+}
+
+// inspectNetwork returns the details of the network with the specified ID,
+// preferably from the cache.
+func (d *discovery) inspectNetwork(ctx context.Context, id string) (types.NetworkResource, error) {
+	details, ok, generation := d.cache.network(id)
+	if ok {
+		return details, nil
+	}
+	if err := d.acquire(ctx); err != nil {
+		return types.NetworkResource{}, err
+	}
+	defer d.release()
+	details, err := d.moby.NetworkInspect(ctx, id, types.NetworkInspectOptions{})
+	if err != nil {
+		return details, err
+	}
+	d.cache.putNetwork(generation, details)
+	return details, nil
+}
@@ -1,5 +1,25 @@
 /*
 Package mobynet implements the discovery of Docker network names and
 container/service labels on these networks, using the Docker API.
+
+Containers sharing the network namespace of another container are followed to
+the container owning the network namespace. Containers in network modes without
+Docker's embedded DNS resolver, such as "host", are reported using a
+[NoEmbeddedDNSError].
+
+Discovery can be limited to specific networks, containers and names using
+[DiscoveryOption]s, such as [WithNetworks], [WithContainerLabels],
+[WithProjects] and [WithNames], as well as their exclusive counterparts.
+
+The attached networks are discovered concurrently, with the number of
+concurrent container and network inspections limited by [WithConcurrency].
+[StreamAttachedNames] passes on the discovered networks as soon as they become
+available, while [DiscoverAttachedNames] waits for all of them. Repeated
+discoveries can share a [Cache] of inspection results using [WithCache], with
+the cache watching the Docker events in order to invalidate changed containers
+and networks.
+
+On Docker's default bridge network, only the legacy links of the center
+container are reported, as container names don't resolve there.
 */
 package mobynet
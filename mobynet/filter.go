@@ -0,0 +1,157 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package mobynet
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// filter decides which networks, containers and names to discover. Empty
+// include lists include everything, while exclusions always take precedence
+// over inclusions.
+type filter struct {
+	includeNetworks []string // network name globs
+	excludeNetworks []string
+	includeLabels   []string // container labels, either "key" or "key=value"
+	excludeLabels   []string
+	includeProjects []string // Docker compose project names
+	excludeProjects []string
+	includeNames    *regexp.Regexp // container names and aliases
+	excludeNames    *regexp.Regexp
+}
+
+// WithNetworks only discovers the attached networks with names matching any
+// of the specified glob patterns, see also [path.Match].
+func WithNetworks(globs ...string) DiscoveryOption {
+	return func(d *discovery) {
+		d.includeNetworks = append(d.includeNetworks, globs...)
+	}
+}
+
+// WithoutNetworks skips the attached networks with names matching any of the
+// specified glob patterns, see also [path.Match].
+func WithoutNetworks(globs ...string) DiscoveryOption {
+	return func(d *discovery) {
+		d.excludeNetworks = append(d.excludeNetworks, globs...)
+	}
+}
+
+// WithContainerLabels only discovers the names of containers having any of the
+// specified labels, either in form of "key" or "key=value".
+func WithContainerLabels(labels ...string) DiscoveryOption {
+	return func(d *discovery) {
+		d.includeLabels = append(d.includeLabels, labels...)
+	}
+}
+
+// WithoutContainerLabels skips the names of containers having any of the
+// specified labels, either in form of "key" or "key=value".
+func WithoutContainerLabels(labels ...string) DiscoveryOption {
+	return func(d *discovery) {
+		d.excludeLabels = append(d.excludeLabels, labels...)
+	}
+}
+
+// WithProjects only discovers the names of containers belonging to any of the
+// specified Docker compose projects.
+func WithProjects(projects ...string) DiscoveryOption {
+	return func(d *discovery) {
+		d.includeProjects = append(d.includeProjects, projects...)
+	}
+}
+
+// WithoutProjects skips the names of containers belonging to any of the
+// specified Docker compose projects.
+func WithoutProjects(projects ...string) DiscoveryOption {
+	return func(d *discovery) {
+		d.excludeProjects = append(d.excludeProjects, projects...)
+	}
+}
+
+// WithNames only discovers the container names and aliases matching the
+// specified regular expression.
+func WithNames(re *regexp.Regexp) DiscoveryOption {
+	return func(d *discovery) {
+		d.includeNames = re
+	}
+}
+
+// WithoutNames skips the container names and aliases matching the specified
+// regular expression.
+func WithoutNames(re *regexp.Regexp) DiscoveryOption {
+	return func(d *discovery) {
+		d.excludeNames = re
+	}
+}
+
+// network returns true if the network with the specified name is to be
+// discovered.
+func (f *filter) network(name string) bool {
+	if matchesAnyGlob(f.excludeNetworks, name) {
+		return false
+	}
+	return len(f.includeNetworks) == 0 || matchesAnyGlob(f.includeNetworks, name)
+}
+
+// container returns true if the names of the container with the specified
+// labels are to be discovered.
+func (f *filter) container(labels map[string]string) bool {
+	if hasAnyLabel(f.excludeLabels, labels) {
+		return false
+	}
+	if len(f.includeLabels) != 0 && !hasAnyLabel(f.includeLabels, labels) {
+		return false
+	}
+	project, ok := labels[ComposeProjectLabel]
+	if ok && contains(f.excludeProjects, project) {
+		return false
+	}
+	return len(f.includeProjects) == 0 || (ok && contains(f.includeProjects, project))
+}
+
+// name returns true if the specified container name or alias is to be
+// discovered.
+func (f *filter) name(name string) bool {
+	if f.excludeNames != nil && f.excludeNames.MatchString(name) {
+		return false
+	}
+	return f.includeNames == nil || f.includeNames.MatchString(name)
+}
+
+// matchesAnyGlob returns true if the specified name matches any of the
+// specified glob patterns. Malformed patterns never match.
+func matchesAnyGlob(globs []string, name string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnyLabel returns true if any of the specified labels in form of "key" or
+// "key=value" is present in the specified container labels.
+func hasAnyLabel(selectors []string, labels map[string]string) bool {
+	for _, selector := range selectors {
+		key, value, withValue := strings.Cut(selector, "=")
+		actual, ok := labels[key]
+		if ok && (!withValue || actual == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns true if the specified list of strings contains s.
+func contains(list []string, s string) bool {
+	for _, el := range list {
+		if el == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package mobynet
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("discovery filters", func() {
+
+	newFilter := func(opts ...DiscoveryOption) *filter {
+		d := &discovery{}
+		for _, opt := range opts {
+			opt(d)
+		}
+		return &d.filter
+	}
+
+	It("includes everything by default", func() {
+		f := newFilter()
+		Expect(f.network("net_A")).To(BeTrue())
+		Expect(f.container(nil)).To(BeTrue())
+		Expect(f.name("foo")).To(BeTrue())
+	})
+
+	It("filters networks by globs", func() {
+		f := newFilter(WithNetworks("net_*"), WithoutNetworks("net_B", "[malformed"))
+		Expect(f.network("net_A")).To(BeTrue())
+		Expect(f.network("net_B")).To(BeFalse())
+		Expect(f.network("bridge")).To(BeFalse())
+	})
+
+	It("filters containers by labels and projects", func() {
+		f := newFilter(WithContainerLabels("messymoby", "tier=backend"), WithoutContainerLabels("skip=true"))
+		Expect(f.container(map[string]string{"messymoby": ""})).To(BeTrue())
+		Expect(f.container(map[string]string{"tier": "backend"})).To(BeTrue())
+		Expect(f.container(map[string]string{"tier": "frontend"})).To(BeFalse())
+		Expect(f.container(map[string]string{"messymoby": "", "skip": "true"})).To(BeFalse())
+
+		f = newFilter(WithProjects("test", "prod"), WithoutProjects("prod"))
+		Expect(f.container(map[string]string{ComposeProjectLabel: "test"})).To(BeTrue())
+		Expect(f.container(map[string]string{ComposeProjectLabel: "prod"})).To(BeFalse())
+		Expect(f.container(map[string]string{})).To(BeFalse())
+
+		f = newFilter(WithoutProjects("prod"))
+		Expect(f.container(map[string]string{})).To(BeTrue())
+	})
+
+	It("filters names by regular expressions", func() {
+		f := newFilter(WithNames(regexp.MustCompile(`^(foo|bar)`)), WithoutNames(regexp.MustCompile(`-\d+$`)))
+		Expect(f.name("foo")).To(BeTrue())
+		Expect(f.name("bar")).To(BeTrue())
+		Expect(f.name("foo-1")).To(BeFalse())
+		Expect(f.name("baz")).To(BeFalse())
+	})
+
+})
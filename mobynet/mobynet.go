@@ -7,97 +7,474 @@ package mobynet
 import (
 	"context"
 	"fmt"
+	"path"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/siemens/mobydig/dig"
+	mobytypes "github.com/siemens/mobydig/types"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 )
 
+// The Docker compose labels identifying the project and service of a container.
+const (
+	ComposeProjectLabel = "com.docker.compose.project"
+	ComposeServiceLabel = "com.docker.compose.service"
+)
+
+// DefaultBridgeOption is the network option marking Docker's default bridge
+// network.
+const DefaultBridgeOption = "com.docker.network.bridge.default_bridge"
+
+// maxNetworkOwnerHops limits following containers sharing the network namespace
+// of other containers.
+const maxNetworkOwnerHops = 8
+
+// NoEmbeddedDNSError reports that a container uses a network mode without
+// Docker's embedded DNS resolver, such as the "host" and "none" network modes,
+// so there are no container and service names to dig.
+type NoEmbeddedDNSError struct {
+	Container   string // name of the container.
+	Owner       string // name of the container owning the network namespace, if different.
+	NetworkMode string // network mode of the owning container.
+}
+
+// Error returns a human-readable description of the network mode lacking an
+// embedded DNS resolver.
+func (e *NoEmbeddedDNSError) Error() string {
+	if e.Owner != "" && e.Owner != e.Container {
+		return fmt.Sprintf("container '%s' shares the network namespace of container '%s' "+
+			"in network mode '%s' without embedded DNS resolver", e.Container, e.Owner, e.NetworkMode)
+	}
+	return fmt.Sprintf("container '%s' uses network mode '%s' without embedded DNS resolver",
+		e.Container, e.NetworkMode)
+}
+
 // DiscoverAttachedNames takes on the position of the “origin” or “center”
 // container identified by centerID and then inspects the networks attached to
 // this container 0. It then queries the containers attached to the attached
-// networks for their container names and aliases.
+// networks for their container names and aliases. Additionally, the driver,
+// options and subnets of the attached networks are returned, so that failures
+// to be expected on such networks can be explained.
+//
+// If the center container shares the network namespace of another container
+// (network mode "container:"), then the networks of the container owning the
+// network namespace are used instead. If the (owning) container is in network
+// mode "host" or "none", then DiscoverAttachedNames returns a
+// [*NoEmbeddedDNSError], as there is no embedded DNS resolver to dig with.
+//
+// Docker's default "bridge" network lacks an embedded DNS resolver, so container
+// names on it cannot be dug. Instead, the center's legacy links (“--link”) are
+// reported for the default bridge network, together with the path of the
+// center's /etc/hosts file Docker writes the link names into.
+//
+// The networks, containers and names to discover can be filtered using
+// [DiscoveryOption]s, such as [WithNetworks] and [WithoutProjects]. Filtering
+// takes place before inspecting filtered networks and thus before any digging
+// or verifying, so that focused checks don't flood Docker's embedded DNS
+// resolver. Please note that legacy link names on the default bridge network
+// are only filtered by their names.
 //
 // This implementation even works correctly in situations with multiple Docker
 // networks having the same name, yet different IDs. Docker networks are
 // different from containers in that network names are not necessarily
 // unambiguous, while container names always are.
-func DiscoverAttachedNames(ctx context.Context, moby *client.Client, centerID string) ([]dig.DockerNetwork, string, error) {
+func DiscoverAttachedNames(ctx context.Context, moby *client.Client, centerID string, opts ...DiscoveryOption) ([]dig.DockerNetwork, string, error) {
+	stream, err := StreamAttachedNames(ctx, moby, centerID, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+	mobyNetworks := []dig.DockerNetwork{}
+	for net := range stream.Networks {
+		mobyNetworks = append(mobyNetworks, net)
+	}
+	if err := stream.Err(); err != nil {
+		return nil, "", err
+	}
+	return mobyNetworks, stream.Netnsref, nil
+}
+
+// NetworkStream streams the networks attached to a center container as they
+// get discovered, see [StreamAttachedNames].
+type NetworkStream struct {
+	Networks <-chan dig.DockerNetwork // discovered networks; closed when done.
+	Netnsref string                   // reference to the center's network namespace.
+	mu       sync.Mutex
+	err      error
+}
+
+// Err returns the first error encountered while discovering the networks, if
+// any. Err must only be called after the Networks channel has been closed.
+func (s *NetworkStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// setErr sets the first error, returning true if it was the first error.
+func (s *NetworkStream) setErr(err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return false
+	}
+	s.err = err
+	return true
+}
+
+// StreamAttachedNames discovers the same networks as [DiscoverAttachedNames],
+// but streams the networks as soon as each network has been inspected, so that
+// digging can start before the discovery has finished. Networks and their
+// containers are inspected concurrently, limited by [WithConcurrency].
+//
+// Errors with respect to the center container itself are returned immediately,
+// while errors during the discovery of the attached networks cut the stream
+// short and are then reported by [NetworkStream.Err].
+func StreamAttachedNames(ctx context.Context, moby *client.Client, centerID string, opts ...DiscoveryOption) (*NetworkStream, error) {
+	d := newDiscovery(moby, opts...)
+
 	// Inspect the specified container in order to get information about the
 	// networks the container currently is attached to.
-	centerDetails, err := moby.ContainerInspect(ctx, centerID)
+	centerDetails, err := d.inspectContainer(ctx, centerID)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	if centerDetails.State.Pid == 0 {
-		return nil, "", fmt.Errorf("container '%s' is not running", centerID)
+		return nil, fmt.Errorf("container '%s' is not running", centerID)
 	}
 
 	centerDetails.Name = strings.TrimPrefix(centerDetails.Name, "/") // argh, Docker's "/name" legacy!
 	netnsref := fmt.Sprintf("/proc/%d/ns/net", centerDetails.State.Pid)
 
-	// In order to avoid repeated inspection of containers that might be
-	// connected to multiple networks the container 0 is also attached to, we
-	// will cache all inspection results.
-	cntrDetailsCache := map[string]types.ContainerJSON{}
-	// Now inspect all attached networks in order to find out which other
-	// containers are attached to them, because these are considered to be
-	// reachable from container 0.
-	mobyNetworks := make([]dig.DockerNetwork, 0, len(centerDetails.NetworkSettings.Networks))
+	// Containers sharing the network namespace of another container don't
+	// have any networks of their own, so we need to follow them to the
+	// container owning the network namespace and use its networks instead.
+	centerDetails, err = d.networkOwner(ctx, centerDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	// Now inspect all attached networks concurrently in order to find out
+	// which other containers are attached to them, because these are
+	// considered to be reachable from container 0. The first error cancels
+	// the remaining inspections.
+	networks := make(chan dig.DockerNetwork)
+	stream := &NetworkStream{
+		Networks: networks,
+		Netnsref: netnsref,
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
 	for attachedNetName, attachedNet := range centerDetails.NetworkSettings.Networks {
-		// Inspecting an attached network gives us all the (other) containers
-		// directly attached to that attached network (including container 0).
-		attNetDetails, err := moby.NetworkInspect(ctx, attachedNet.NetworkID, types.NetworkInspectOptions{})
-		if err != nil {
-			return nil, "", err
-		}
-		if len(attNetDetails.Containers) == 0 {
-			continue // do not create return empty networks
-		}
-		// All the names (DNS labels) on this network: since service names might
-		// refer to multiple containers, we cannot use a simple slice, but
-		// instead need to ensure that each DNS label will appear only once in
-		// the final list. And nobody expects ... Captn Map!
-		namesOnNetwork := map[string]struct{}{}
-		// Now inspect the containers attached to this network attached to
-		// container 0. These additional inspections become necessary, as the
-		// attached network inspection doesn't reveal the container aliases, but
-		// only the container names ... and not even the container IDs.
-		for _, attCntr := range attNetDetails.Containers {
-			// Well, do not add our own container label to the resulting list.
-			if attCntr.Name == centerDetails.Name {
-				continue
+		if !d.network(attachedNetName) {
+			continue
+		}
+		wg.Add(1)
+		go func(attachedNetName string, networkID string) {
+			defer wg.Done()
+			net, ok, err := d.discoverNetwork(ctx, centerDetails, attachedNetName, networkID)
+			if err != nil {
+				if stream.setErr(err) {
+					cancel()
+				}
+				return
 			}
-			// the link from a network to an attached container is by container
-			// name, but not container ID. Anyway, see if we have something in
-			// our cache, otherwise get the ugly container details and then
-			// cache them.
-			attCntrDetails, ok := cntrDetailsCache[attCntr.Name]
 			if !ok {
-				attCntrDetails, err = moby.ContainerInspect(ctx, attCntr.Name)
-				if err != nil {
-					continue
-				}
-				cntrDetailsCache[attCntr.Name] = attCntrDetails
+				return
+			}
+			select {
+			case networks <- net:
+			case <-ctx.Done():
+				stream.setErr(ctx.Err())
+			}
+		}(attachedNetName, attachedNet.NetworkID)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+		close(networks)
+	}()
+	return stream, nil
+}
+
+// discoverNetwork inspects the specified network attached to the center
+// container, as well as the containers attached to this network. It returns
+// false if the network is to be skipped, such as when there are no other
+// containers attached to it.
+func (d *discovery) discoverNetwork(
+	ctx context.Context, centerDetails types.ContainerJSON, attachedNetName string, networkID string,
+) (dig.DockerNetwork, bool, error) {
+	// Inspecting an attached network gives us all the (other) containers
+	// directly attached to that attached network (including container 0).
+	attNetDetails, err := d.inspectNetwork(ctx, networkID)
+	if err != nil {
+		return dig.DockerNetwork{}, false, err
+	}
+	if len(attNetDetails.Containers) == 0 {
+		return dig.DockerNetwork{}, false, nil // do not create return empty networks
+	}
+	isDefault := isDefaultBridge(attNetDetails)
+	// Now inspect the containers attached to this network attached to
+	// container 0. These additional inspections become necessary, as the
+	// attached network inspection doesn't reveal the container aliases, but
+	// only the container names ... and not even the container IDs. As these
+	// inspections are independent of each other, we run them concurrently.
+	attCntrs := make([]types.EndpointResource, 0, len(attNetDetails.Containers))
+	for _, attCntr := range attNetDetails.Containers {
+		// Well, do not add our own container label to the resulting list.
+		if attCntr.Name == centerDetails.Name {
+			continue
+		}
+		attCntrs = append(attCntrs, attCntr)
+	}
+	attCntrsDetails := make([]*types.ContainerJSON, len(attCntrs))
+	var wg sync.WaitGroup
+	for idx := range attCntrs {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			// the link from a network to an attached container is by container
+			// name, but not container ID. Containers vanishing in the meantime
+			// are simply skipped.
+			details, err := d.inspectContainer(ctx, attCntrs[idx].Name)
+			if err != nil {
+				return
+			}
+			attCntrsDetails[idx] = &details
+		}(idx)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return dig.DockerNetwork{}, false, ctx.Err()
+	}
+
+	// All the names (DNS labels) on this network: since service names might
+	// refer to multiple containers, we cannot use a simple slice, but instead
+	// need to ensure that each DNS label will appear only once in the final
+	// list. And nobody expects ... Captn Map!
+	namesOnNetwork := map[string]struct{}{}
+	// As a container name on one container might well be an alias on another
+	// container, a label only counts as an alias if it isn't also a container
+	// name on this network.
+	aliasesOnNetwork := map[string]struct{}{}
+	endpoints := map[string]mobytypes.ContainerInfo{}
+	for idx, attCntr := range attCntrs {
+		attCntrDetails := attCntrsDetails[idx]
+		if attCntrDetails == nil {
+			continue
+		}
+		cntrInfo := containerInfo(*attCntrDetails)
+		for _, addr := range []string{attCntr.IPv4Address, attCntr.IPv6Address} {
+			if addr == "" {
+				continue
 			}
+			// Endpoint addresses come in CIDR notation, so cut off the prefix
+			// length.
+			addr, _, _ = strings.Cut(addr, "/")
+			endpoints[addr] = cntrInfo
+		}
+		// Container names and aliases on the default bridge network don't
+		// resolve, so we only take the center's links later.
+		if isDefault {
+			continue
+		}
+		var labels map[string]string
+		if attCntrDetails.Config != nil {
+			labels = attCntrDetails.Config.Labels
+		}
+		if !d.container(labels) {
+			continue
+		}
+		if d.name(attCntr.Name) {
 			namesOnNetwork[attCntr.Name] = struct{}{}
-			for _, alias := range attCntrDetails.NetworkSettings.Networks[attachedNetName].Aliases {
-				namesOnNetwork[alias] = struct{}{}
+		}
+		for _, alias := range attCntrDetails.NetworkSettings.Networks[attachedNetName].Aliases {
+			if !d.name(alias) {
+				continue
+			}
+			namesOnNetwork[alias] = struct{}{}
+			aliasesOnNetwork[alias] = struct{}{}
+		}
+	}
+	for _, attCntr := range attNetDetails.Containers {
+		delete(aliasesOnNetwork, attCntr.Name)
+	}
+	hostsFile := ""
+	if isDefault {
+		namesOnNetwork, aliasesOnNetwork = linkNames(centerDetails)
+		for name := range namesOnNetwork {
+			if !d.name(name) {
+				delete(namesOnNetwork, name)
+				delete(aliasesOnNetwork, name)
 			}
 		}
-		// Add the DNS label-related information about this Docker network to
-		// the result.
-		dnsLabels := make([]string, 0, len(namesOnNetwork))
-		for alias := range namesOnNetwork {
-			dnsLabels = append(dnsLabels, alias)
+		if len(namesOnNetwork) == 0 {
+			return dig.DockerNetwork{}, false, nil // no links, so nothing to look up.
 		}
-		mobyNetworks = append(mobyNetworks, dig.DockerNetwork{
-			Label:  attachedNetName,
-			Labels: dnsLabels,
+		hostsFile = hostsFilePath(centerDetails.State.Pid)
+	}
+	// Return the DNS label-related information about this Docker network.
+	dnsLabels := make([]string, 0, len(namesOnNetwork))
+	for alias := range namesOnNetwork {
+		dnsLabels = append(dnsLabels, alias)
+	}
+	aliases := make([]string, 0, len(aliasesOnNetwork))
+	for alias := range aliasesOnNetwork {
+		aliases = append(aliases, alias)
+	}
+	subnets := make([]dig.Subnet, 0, len(attNetDetails.IPAM.Config))
+	for _, config := range attNetDetails.IPAM.Config {
+		if config.Subnet == "" {
+			continue
+		}
+		subnets = append(subnets, dig.Subnet{
+			Subnet:  config.Subnet,
+			Gateway: config.Gateway,
 		})
 	}
-	return mobyNetworks, netnsref, nil
+	return dig.DockerNetwork{
+		Label:      attachedNetName,
+		ID:         networkID,
+		Labels:     dnsLabels,
+		Aliases:    aliases,
+		Endpoints:  endpoints,
+		Default:    isDefault,
+		HostsFile:  hostsFile,
+		Driver:     attNetDetails.Driver,
+		Internal:   attNetDetails.Internal,
+		EnableIPv6: attNetDetails.EnableIPv6,
+		Subnets:    subnets,
+	}, true, nil
+}
+
+// CenterHostsFile returns the path of the /etc/hosts file of the container
+// identified by centerID, as seen from the host through the container's root
+// directory in /proc/<pid>/root. Please note that the center container itself
+// needs to be inspected for its hosts file, as Docker's “--add-host” and
+// “extra_hosts” are per container.
+//
+// Additionally, CenterHostsFile returns the host names of the center container
+// itself, which Docker writes into its hosts file, too: the host name (which
+// defaults to the short container ID), the host name with the domain name, if
+// any, and the short container ID.
+func CenterHostsFile(ctx context.Context, moby *client.Client, centerID string) (string, []string, error) {
+	centerDetails, err := moby.ContainerInspect(ctx, centerID)
+	if err != nil {
+		return "", nil, err
+	}
+	if centerDetails.State.Pid == 0 {
+		return "", nil, fmt.Errorf("container '%s' is not running", centerID)
+	}
+	return hostsFilePath(centerDetails.State.Pid), hostnames(centerDetails), nil
+}
+
+// hostnames returns the host names of the container with the specified
+// details, as Docker writes them into the container's /etc/hosts.
+func hostnames(details types.ContainerJSON) []string {
+	names := []string{}
+	if details.Config != nil && details.Config.Hostname != "" {
+		names = append(names, details.Config.Hostname)
+		if details.Config.Domainname != "" {
+			names = append(names, details.Config.Hostname+"."+details.Config.Domainname)
+		}
+	}
+	if len(details.ID) >= 12 && !slices.Contains(names, details.ID[:12]) {
+		names = append(names, details.ID[:12])
+	}
+	return names
+}
+
+// hostsFilePath returns the path of the /etc/hosts file of the container with
+// the specified PID, as seen from the host.
+func hostsFilePath(pid int) string {
+	return fmt.Sprintf("/proc/%d/root/etc/hosts", pid)
+}
+
+// networkOwner returns the details of the container owning the network
+// namespace of the container with the specified details. This is the container
+// itself, unless it is in "container:" network mode. networkOwner returns a
+// [*NoEmbeddedDNSError] if the owner is in a network mode without embedded DNS
+// resolver.
+func (d *discovery) networkOwner(ctx context.Context, details types.ContainerJSON) (types.ContainerJSON, error) {
+	name := details.Name
+	for hops := 0; details.HostConfig != nil; hops++ {
+		mode := details.HostConfig.NetworkMode
+		switch {
+		case mode.IsHost(), mode.IsNone():
+			return details, &NoEmbeddedDNSError{
+				Container:   name,
+				Owner:       details.Name,
+				NetworkMode: string(mode),
+			}
+		case mode.IsContainer():
+			if hops >= maxNetworkOwnerHops {
+				return details, fmt.Errorf("container '%s' shares network namespaces too deeply", name)
+			}
+			owner := mode.ConnectedContainer()
+			var err error
+			details, err = d.inspectContainer(ctx, owner)
+			if err != nil {
+				return details, fmt.Errorf("cannot inspect container '%s' owning the network namespace of container '%s': %w",
+					owner, name, err)
+			}
+			details.Name = strings.TrimPrefix(details.Name, "/")
+		default:
+			return details, nil
+		}
+	}
+	return details, nil
+}
+
+// isDefaultBridge returns true if the network with the specified details is
+// Docker's default bridge network.
+func isDefaultBridge(details types.NetworkResource) bool {
+	if opt, ok := details.Options[DefaultBridgeOption]; ok {
+		return opt == "true"
+	}
+	return details.Name == "bridge" && details.Driver == "bridge"
+}
+
+// linkNames returns the names of the legacy links of the container with the
+// specified details, as well as which of them are link aliases. Docker lists
+// links in the form "/target:/container/alias", where both the target
+// container name as well as the alias resolve via the container's /etc/hosts.
+func linkNames(details types.ContainerJSON) (names, aliases map[string]struct{}) {
+	names = map[string]struct{}{}
+	aliases = map[string]struct{}{}
+	if details.HostConfig == nil {
+		return
+	}
+	for _, link := range details.HostConfig.Links {
+		target, alias, ok := strings.Cut(link, ":")
+		if !ok {
+			continue
+		}
+		target = strings.TrimPrefix(target, "/")
+		alias = path.Base(alias)
+		names[target] = struct{}{}
+		names[alias] = struct{}{}
+		if alias != target {
+			aliases[alias] = struct{}{}
+		}
+	}
+	return
+}
+
+// containerInfo returns the identity of the container with the specified
+// inspection details.
+func containerInfo(details types.ContainerJSON) mobytypes.ContainerInfo {
+	info := mobytypes.ContainerInfo{
+		ID:   details.ID,
+		Name: strings.TrimPrefix(details.Name, "/"),
+	}
+	if details.Config != nil {
+		info.Project = details.Config.Labels[ComposeProjectLabel]
+		info.Service = details.Config.Labels[ComposeServiceLabel]
+		info.Image = details.Config.Image
+	}
+	return info
 }
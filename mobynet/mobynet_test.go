@@ -6,8 +6,14 @@ package mobynet
 
 import (
 	"context"
+	"errors"
+	"regexp"
 	"time"
 
+	"github.com/siemens/mobydig/dig"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -38,7 +44,17 @@ var _ = Describe("Docker networks", func() {
 		Expect(dnets).To(ContainElements(
 			And(
 				HaveField("Label", "net_A"),
+				HaveField("ID", Not(BeEmpty())),
+				HaveField("Driver", "bridge"),
+				HaveField("Subnets", Not(BeEmpty())),
 				HaveField("Labels", ContainElements("foo", "test-foo-1", "test-foo-2")),
+				HaveField("Aliases", And(ContainElement("foo"), Not(ContainElement("test-foo-1")))),
+				HaveField("Endpoints", ContainElement(And(
+					HaveField("Name", "test-foo-1"),
+					HaveField("Project", "test"),
+					HaveField("Service", "foo"),
+					HaveField("ID", Not(BeEmpty())),
+				))),
 			),
 			And(
 				HaveField("Label", "net_B"),
@@ -51,4 +67,121 @@ var _ = Describe("Docker networks", func() {
 		))
 	})
 
+	It("filters networks and names", NodeTimeout(30*time.Second), func(ctx context.Context) {
+		cln := Successful(client.NewClientWithOpts(
+			client.WithHost("unix:///var/run/docker.sock"),
+			client.WithAPIVersionNegotiation(),
+		))
+		defer cln.Close()
+		dnets, _ := Successful2R(DiscoverAttachedNames(ctx, cln, "test-test-1",
+			WithoutNetworks("net_B"),
+			WithNames(regexp.MustCompile(`^foo$`))))
+		Expect(dnets).To(ConsistOf(
+			And(HaveField("Label", "net_A"), HaveField("Labels", ConsistOf("foo"))),
+			And(HaveField("Label", "net_C"), HaveField("Labels", ConsistOf("foo"))),
+		))
+	})
+
+	It("streams attached networks using a shared cache", NodeTimeout(30*time.Second), func(ctx context.Context) {
+		cln := Successful(client.NewClientWithOpts(
+			client.WithHost("unix:///var/run/docker.sock"),
+			client.WithAPIVersionNegotiation(),
+		))
+		defer cln.Close()
+		cache := NewCache()
+		watchctx, cancel := context.WithCancel(ctx)
+		watchDone := make(chan struct{})
+		go func() {
+			defer close(watchDone)
+			_ = cache.Watch(watchctx, cln)
+		}()
+		defer func() {
+			cancel()
+			<-watchDone
+		}()
+		for i := 0; i < 2; i++ {
+			stream := Successful(StreamAttachedNames(ctx, cln, "test-test-1",
+				WithCache(cache), WithConcurrency(2)))
+			Expect(stream.Netnsref).To(MatchRegexp(`^/proc/\d+/ns/net$`))
+			var dnets []dig.DockerNetwork
+			for dnet := range stream.Networks {
+				dnets = append(dnets, dnet)
+			}
+			Expect(stream.Err()).To(Succeed())
+			Expect(dnets).To(ConsistOf(
+				HaveField("Label", "net_A"),
+				HaveField("Label", "net_B"),
+				HaveField("Label", "net_C"),
+			))
+		}
+	})
+
+	It("follows shared network namespaces", NodeTimeout(30*time.Second), func(ctx context.Context) {
+		cln := Successful(client.NewClientWithOpts(
+			client.WithHost("unix:///var/run/docker.sock"),
+			client.WithAPIVersionNegotiation(),
+		))
+		defer cln.Close()
+		dnets, _ := Successful2R(DiscoverAttachedNames(ctx, cln, "test-sharey-1"))
+		Expect(dnets).To(ContainElements(
+			HaveField("Label", "net_A"),
+			HaveField("Label", "net_B"),
+			HaveField("Label", "net_C"),
+		))
+		Expect(dnets).To(HaveEach(HaveField("Labels", Not(ContainElement("test-test-1")))))
+	})
+
+	It("reports network modes without embedded DNS", NodeTimeout(30*time.Second), func(ctx context.Context) {
+		cln := Successful(client.NewClientWithOpts(
+			client.WithHost("unix:///var/run/docker.sock"),
+			client.WithAPIVersionNegotiation(),
+		))
+		defer cln.Close()
+		_, _, err := DiscoverAttachedNames(ctx, cln, "test-hosty-1")
+		var dnserr *NoEmbeddedDNSError
+		Expect(errors.As(err, &dnserr)).To(BeTrue())
+		Expect(dnserr.NetworkMode).To(Equal("host"))
+		Expect(err).To(MatchError(ContainSubstring("without embedded DNS resolver")))
+	})
+
+	It("parses legacy links", func() {
+		names, aliases := linkNames(types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				HostConfig: &container.HostConfig{
+					Links: []string{"/test-foo-1:/test-test-1/foo", "/test-bar-1:/test-test-1/test-bar-1"},
+				},
+			},
+		})
+		Expect(names).To(HaveLen(3))
+		Expect(names).To(HaveKey("foo"))
+		Expect(names).To(HaveKey("test-foo-1"))
+		Expect(names).To(HaveKey("test-bar-1"))
+		Expect(aliases).To(HaveLen(1))
+		Expect(aliases).To(HaveKey("foo"))
+	})
+
+	It("recognizes the default bridge network", func() {
+		Expect(isDefaultBridge(types.NetworkResource{
+			Name: "bridge", Driver: "bridge",
+		})).To(BeTrue())
+		Expect(isDefaultBridge(types.NetworkResource{
+			Name: "bridge", Driver: "bridge", Options: map[string]string{DefaultBridgeOption: "false"},
+		})).To(BeFalse())
+		Expect(isDefaultBridge(types.NetworkResource{
+			Name: "docker0", Driver: "bridge", Options: map[string]string{DefaultBridgeOption: "true"},
+		})).To(BeTrue())
+		Expect(isDefaultBridge(types.NetworkResource{Name: "net_A", Driver: "bridge"})).To(BeFalse())
+	})
+
+	It("returns the host names of containers", func() {
+		Expect(hostnames(types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{ID: "0123456789abcdef"},
+			Config:            &container.Config{Hostname: "0123456789ab"},
+		})).To(ConsistOf("0123456789ab"))
+		Expect(hostnames(types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{ID: "0123456789abcdef"},
+			Config:            &container.Config{Hostname: "center", Domainname: "example.com"},
+		})).To(ConsistOf("center", "center.example.com", "0123456789ab"))
+	})
+
 })
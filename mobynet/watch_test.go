@@ -0,0 +1,191 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package mobynet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/siemens/mobydig/dig"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+// fakeMoby is a minimal fake Docker API serving container and network
+// inspections as well as events, counting the inspections.
+type fakeMoby struct {
+	mu          sync.Mutex
+	containers  map[string]types.ContainerJSON   // by name.
+	networks    map[string]types.NetworkResource // by ID.
+	inspections map[string]int                   // by container name or network ID.
+	events      chan events.Message
+	subscribed  chan struct{} // closed when the event stream has been requested.
+}
+
+var apiVersionPrefix = regexp.MustCompile(`^/v[0-9.]+`)
+
+func (m *fakeMoby) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := apiVersionPrefix.ReplaceAllString(req.URL.Path, "")
+	switch {
+	case path == "/_ping":
+		w.Header().Set("Api-Version", "1.41")
+		w.WriteHeader(http.StatusOK)
+	case path == "/events":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		close(m.subscribed)
+		enc := json.NewEncoder(w)
+		for {
+			select {
+			case ev := <-m.events:
+				_ = enc.Encode(ev)
+				w.(http.Flusher).Flush()
+			case <-req.Context().Done():
+				return
+			}
+		}
+	case strings.HasPrefix(path, "/containers/") && strings.HasSuffix(path, "/json"):
+		name := strings.TrimSuffix(strings.TrimPrefix(path, "/containers/"), "/json")
+		m.mu.Lock()
+		details, ok := m.containers[name]
+		m.inspections[name]++
+		m.mu.Unlock()
+		m.reply(w, details, ok)
+	case strings.HasPrefix(path, "/networks/"):
+		id := strings.TrimPrefix(path, "/networks/")
+		m.mu.Lock()
+		details, ok := m.networks[id]
+		m.inspections[id]++
+		m.mu.Unlock()
+		m.reply(w, details, ok)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (m *fakeMoby) reply(w http.ResponseWriter, details any, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "no such object"})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(details)
+}
+
+// Inspections returns (a copy of) the inspection counts so far.
+func (m *fakeMoby) Inspections() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := map[string]int{}
+	for key, count := range m.inspections {
+		counts[key] = count
+	}
+	return counts
+}
+
+// SetAliases changes the aliases of the specified container on the specified
+// network.
+func (m *fakeMoby) SetAliases(name string, netName string, aliases ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	details := m.containers[name]
+	netdetails := *details.NetworkSettings.Networks[netName]
+	netdetails.Aliases = aliases
+	details.NetworkSettings = &types.NetworkSettings{
+		Networks: map[string]*network.EndpointSettings{netName: &netdetails},
+	}
+	m.containers[name] = details
+}
+
+var _ = Describe("watching discovery cache", func() {
+
+	cntr := func(id, name string, pid int, netName string, netID string, aliases ...string) types.ContainerJSON {
+		return types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				ID:    id,
+				Name:  "/" + name,
+				State: &types.ContainerState{Pid: pid},
+			},
+			NetworkSettings: &types.NetworkSettings{
+				Networks: map[string]*network.EndpointSettings{
+					netName: {NetworkID: netID, Aliases: aliases},
+				},
+			},
+		}
+	}
+
+	It("reuses inspections between discoveries until events invalidate them", NodeTimeout(30*time.Second), func(ctx context.Context) {
+		fake := &fakeMoby{
+			containers: map[string]types.ContainerJSON{
+				"center": cntr("c0", "center", 42, "net_A", "n1"),
+				"foo":    cntr("c1", "foo", 666, "net_A", "n1", "bar"),
+			},
+			networks: map[string]types.NetworkResource{
+				"n1": {
+					Name: "net_A",
+					ID:   "n1",
+					Containers: map[string]types.EndpointResource{
+						"c0": {Name: "center", IPv4Address: "172.16.0.1/16"},
+						"c1": {Name: "foo", IPv4Address: "172.16.0.2/16"},
+					},
+				},
+			},
+			inspections: map[string]int{},
+			events:      make(chan events.Message),
+			subscribed:  make(chan struct{}),
+		}
+		server := httptest.NewServer(fake)
+		DeferCleanup(server.Close)
+		moby := Successful(client.NewClientWithOpts(
+			client.WithHost("tcp://"+server.Listener.Addr().String()),
+			client.WithAPIVersionNegotiation()))
+		DeferCleanup(moby.Close)
+
+		cache := NewCache()
+		watchctx, cancel := context.WithCancel(ctx)
+		DeferCleanup(cancel)
+		go func() { _ = cache.Watch(watchctx, moby) }()
+		Eventually(fake.subscribed).WithContext(ctx).Should(BeClosed())
+
+		discover := func() []dig.DockerNetwork {
+			GinkgoHelper()
+			networks, _, err := DiscoverAttachedNames(ctx, moby, "center", WithCache(cache))
+			Expect(err).NotTo(HaveOccurred())
+			return networks
+		}
+
+		By("discovering twice without any changes")
+		Expect(discover()).To(ConsistOf(HaveField("Labels", ConsistOf("foo", "bar"))))
+		Expect(discover()).To(ConsistOf(HaveField("Labels", ConsistOf("foo", "bar"))))
+		Expect(fake.Inspections()).To(Equal(map[string]int{"center": 1, "foo": 1, "n1": 1}))
+
+		By("reconnecting a container with a different alias")
+		fake.SetAliases("foo", "net_A", "baz")
+		fake.events <- events.Message{
+			Type:   events.NetworkEventType,
+			Action: events.ActionConnect,
+			Actor:  events.Actor{ID: "n1", Attributes: map[string]string{"container": "c1"}},
+		}
+		Eventually(discover).WithContext(ctx).Should(
+			ConsistOf(HaveField("Labels", ConsistOf("foo", "baz"))))
+		Expect(fake.Inspections()).To(Equal(map[string]int{"center": 1, "foo": 2, "n1": 2}))
+	})
+
+})
@@ -8,6 +8,12 @@ consists of (at least) an IP address as well as the [types.Quality] state,
 notably [types.Valid] and Invalid, but also [types.Verifying] and (initially)
 [types.Unverified].
 
+Besides the binary verdicts of verified and invalid, a Pinger tells apart
+addresses that are reachable, yet suffer from losses above a soft threshold or
+high round-trip times ([types.Degraded]) from addresses that don't answer at all
+([types.Unreachable]). Addresses that cannot be pinged in the first place, such
+as unresolvable names, are [types.Invalid].
+
 	         +---+
 	string-->| P +-->ch QualifiedAddress
 	         +---+
@@ -29,6 +35,16 @@ Pingers can be operated in a pipeline in that they read the addresses to be
 validated from an input channel and then stream the results to the Pinger's
 output channel.
 
+# Payloads
+
+[PingerOf] is the generic form of [Pinger], streaming verdicts of the concrete
+address type it was instantiated with, such as
+[types.QualifiedAddressWith] carrying an application-specific payload. Pinger
+wraps a PingerOf[types.QualifiedAddress], additionally validating plain IP
+address strings using [Pinger.Validate].
+
+	pinger, courtTV := ping.NewOf[*types.QualifiedAddressWith[MyPayload]](42)
+
 # Acknowledgements
 
 Under its hood, [Pinger] leverages [gammazero/workerpool] as the limiting
@@ -8,6 +8,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"strconv"
 	"sync"
 	"time"
 
@@ -20,22 +22,48 @@ import (
 	"github.com/thediveo/lxkns/species"
 )
 
+// PingerOf validates IP addresses by pinging them and then streaming the final
+// verdicts of type Q to a result/output channel (kind of “IT-court TV”).
+// PingerOfs use a goroutine-limited worker pool.
+//
+// Q is the concrete type of addresses to validate, such as
+// [types.NamedAddressWith] carrying an application-specific payload. Please
+// note that Q's WithNewQuality method must return another Q (and, if Q
+// implements [types.Stamper], so must its WithProvenance method).
+type PingerOf[Q types.QualifiedAddress] struct {
+	pingerConfig
+	workers  *workerpool.WorkerPool // DNS workers for running incoming validation jobs concurrently.
+	courtTV  chan Q                 // results/status stream channel.
+	stopOnce sync.Once
+}
+
 // Pinger validates IP addresses by pinging them and then streaming the final
 // [types.QualifiedAddress] verdicts to a result/output channel (kind of
 // “IT-court TV”). Pingers use a goroutine-limited worker pool.
+//
+// In contrast to a PingerOf, a Pinger can additionally validate plain IP
+// address strings using [Pinger.Validate].
 type Pinger struct {
+	*PingerOf[types.QualifiedAddress]
+}
+
+// pingerConfig is the configuration of a PingerOf, regardless of the concrete
+// type of addresses validated.
+type pingerConfig struct {
 	count               int           // number of pings to send.
 	interval            time.Duration // distance between pings.
 	thresholdPercentage uint          // percentage of successful pings for valid IP address.
+	softPercentage      uint          // percentage of successful pings below which an IP address is degraded.
+	maxRTT              time.Duration // average round-trip time above which an IP address is degraded, if non-zero.
 	unprivileged        bool          // if true, uses UDP-based pings instead of privileged ICMPs.
+	tcpPort             uint16        // if non-zero, probes TCP connects to this port instead of pinging.
 
-	netns    relations.Relation          // network namespace to ping from, or nil.
-	workers  *workerpool.WorkerPool      // DNS workers for running incoming validation jobs concurrently.
-	courtTV  chan types.QualifiedAddress // results/status stream channel.
-	stopOnce sync.Once
+	netns  relations.Relation // network namespace to ping from, or nil.
+	origin string             // network namespace reference to ping from, if any.
 }
 
-// PingerOption can be passed to New when creating new Pinger objects.
+// PingerOption can be passed to New and NewOf when creating new Pinger and
+// PingerOf objects.
 type PingerOption func(*Pinger)
 
 // New returns a new [Pinger] with a maximum worker pool of the specified size
@@ -44,13 +72,18 @@ type PingerOption func(*Pinger)
 // addresses as they get submitted for ping court verdicts.
 //
 // The new pinger defaults to pinging 3 times at intervals of 1s between each
-// ping. The validity threshold defaults to 50(%).
+// ping. The validity threshold defaults to 50(%). The soft threshold below
+// which reachable addresses are considered to be degraded defaults to 0(%),
+// that is, losses never degrade reachable addresses unless opted in.
 //
 // The pinger can be configured during creation using several option:
 //   - [WithCount]
 //   - [WithInterval]
 //   - [WithThresholdPercentage]
+//   - [WithSoftThresholdPercentage]
+//   - [WithMaxRTT]
 //   - [AsUnprivileged]
+//   - [WithTCPProbe]
 //
 // To operate a Pinger in a network namespace different to that of the OS-level
 // thread of the caller specify the InNetworkNamespace option and pass it a
@@ -60,21 +93,43 @@ func New(size int, options ...PingerOption) (*Pinger, <-chan types.QualifiedAddr
 	return new(size, size, options...)
 }
 
+// NewOf returns a new [PingerOf] validating addresses of type Q, with a maximum
+// worker pool of the specified size as well as a “verdict stream” of Qs. It
+// otherwise works the same as [New], accepting the same options.
+func NewOf[Q types.QualifiedAddress](size int, options ...PingerOption) (*PingerOf[Q], <-chan Q) {
+	return newOf[Q](size, size, options...)
+}
+
 // new returns a new [Pinger] with a maximum worker pool of the specified size and
 // a “verdict stream” with the specified buffer size.
 func new(workersize int, chansize int, options ...PingerOption) (*Pinger, <-chan types.QualifiedAddress) {
-	courtTV := make(chan types.QualifiedAddress, chansize)
-	pinger := &Pinger{
-		count:               3,
-		interval:            time.Second,
-		thresholdPercentage: 50,
-		workers:             workerpool.New(workersize),
-		courtTV:             courtTV,
+	pinger, courtTV := newOf[types.QualifiedAddress](workersize, chansize, options...)
+	return &Pinger{PingerOf: pinger}, courtTV
+}
+
+// newOf returns a new [PingerOf] with a maximum worker pool of the specified
+// size and a “verdict stream” with the specified buffer size.
+func newOf[Q types.QualifiedAddress](workersize int, chansize int, options ...PingerOption) (*PingerOf[Q], <-chan Q) {
+	// As the options work on Pingers, we apply them to a stand-in Pinger and
+	// then take over only its configuration.
+	cfg := Pinger{
+		PingerOf: &PingerOf[types.QualifiedAddress]{
+			pingerConfig: pingerConfig{
+				count:               3,
+				interval:            time.Second,
+				thresholdPercentage: 50,
+			},
+		},
 	}
 	for _, opt := range options {
-		opt(pinger)
+		opt(&cfg)
 	}
-	return pinger, courtTV
+	courtTV := make(chan Q, chansize)
+	return &PingerOf[Q]{
+		pingerConfig: cfg.pingerConfig,
+		workers:      workerpool.New(workersize),
+		courtTV:      courtTV,
+	}, courtTV
 }
 
 // InNetworkNamespace optionally runs a [Pinger] inside the network namespace
@@ -82,6 +137,7 @@ func new(workersize int, chansize int, options ...PingerOption) (*Pinger, <-chan
 func InNetworkNamespace(netnsref string) PingerOption {
 	return func(p *Pinger) {
 		p.netns = ops.NewTypedNamespacePath(netnsref, species.CLONE_NEWNET)
+		p.origin = netnsref
 	}
 }
 
@@ -107,6 +163,18 @@ func AsUnprivileged() PingerOption {
 	}
 }
 
+// WithTCPProbe tells the Pinger to probe addresses by connecting to the
+// specified TCP port instead of sending ICMP pings. This is useful for hosts
+// outside Docker networks that are reachable, yet silently drop ICMP pings, as
+// is often the case with registries or other (internal) APIs. The number of
+// probes, the interval between them, as well as the validity threshold work the
+// same as for ping-based probes.
+func WithTCPProbe(port uint16) PingerOption {
+	return func(p *Pinger) {
+		p.tcpPort = port
+	}
+}
+
 // WithThresholdPercentage takes a percentage between 0 and 100 that specifies
 // the percentage of successful ping responses required in order to validate the
 // pinged IP address.
@@ -120,6 +188,31 @@ func WithThresholdPercentage(threshold uint) PingerOption {
 	}
 }
 
+// WithSoftThresholdPercentage takes a percentage between 0 and 100 that
+// specifies the percentage of successful ping responses below which a
+// reachable IP address is considered to be [types.Degraded] instead of
+// [types.Verified]. The soft threshold only applies to addresses that reach the
+// (hard) validity threshold set using [WithThresholdPercentage]. It defaults to
+// 0, thus never degrading addresses because of losses.
+func WithSoftThresholdPercentage(threshold uint) PingerOption {
+	if threshold > 100 {
+		panic(fmt.Errorf("Pinger: soft threshold must be a percentage between 0 <= threshold <= 100, got: %d",
+			threshold))
+	}
+	return func(p *Pinger) {
+		p.softPercentage = threshold
+	}
+}
+
+// WithMaxRTT sets the average round-trip time above which a reachable IP
+// address is considered to be [types.Degraded] instead of [types.Verified]. A
+// zero duration disables checking round-trip times.
+func WithMaxRTT(rtt time.Duration) PingerOption {
+	return func(p *Pinger) {
+		p.maxRTT = rtt
+	}
+}
+
 // ValidateStream reads addresses (with optional attachments) to be validated
 // from a channel until the channel is closed. It does not return until the
 // channel has been closed, so callers typically might run ValidateStream in a
@@ -127,7 +220,7 @@ func WithThresholdPercentage(threshold uint) PingerOption {
 //
 // The input channel transmits [types.QualifiedAddress] objects, but with the
 // Quality field initially ignored.
-func (p *Pinger) ValidateStream(ch <-chan types.QualifiedAddress) {
+func (p *PingerOf[Q]) ValidateStream(ch <-chan Q) {
 	p.ValidateStreamContext(context.Background(), ch)
 }
 
@@ -145,7 +238,7 @@ func (p *Pinger) ValidateStream(ch <-chan types.QualifiedAddress) {
 //
 // The input channel transmits [QualifiedAddress] objects, but with the Quality
 // field initially ignored.
-func (p *Pinger) ValidateStreamContext(ctx context.Context, ch <-chan types.QualifiedAddress) {
+func (p *PingerOf[Q]) ValidateStreamContext(ctx context.Context, ch <-chan Q) {
 	for {
 		select {
 		case addr, ok := <-ch:
@@ -183,6 +276,10 @@ func (p *Pinger) ValidateStreamContext(ctx context.Context, ch <-chan types.Qual
 // The validation process is automatically aborted when the specified context
 // either meets its deadline or gets cancelled. The IP address is then
 // considered to be Invalid.
+//
+// Validate is only available on a [Pinger], as it creates plain
+// [types.QualifiedAddressValue] verdicts; a PingerOf for a concrete address
+// type validates its addresses using [PingerOf.ValidateQA] instead.
 func (p *Pinger) Validate(ctx context.Context, addr string) {
 	p.validate(ctx, &types.QualifiedAddressValue{
 		Address: addr,
@@ -191,7 +288,7 @@ func (p *Pinger) Validate(ctx context.Context, addr string) {
 }
 
 // ValidateQA validates the specified [types.QualifiedAddress] and works
-// otherwise like [Validate] for a plain address string.
+// otherwise like [Pinger.Validate] for a plain address string.
 //
 // If the specified context gets cancelled the pending address verifications
 // won't be echoed to the verdict stream at all, and in particular not even as
@@ -202,7 +299,7 @@ func (p *Pinger) Validate(ctx context.Context, addr string) {
 // The validation process is automatically aborted when the specified context
 // either meets its deadline or gets cancelled. The IP address is then
 // considered to be Invalid.
-func (p *Pinger) ValidateQA(ctx context.Context, addr types.QualifiedAddress) {
+func (p *PingerOf[Q]) ValidateQA(ctx context.Context, addr Q) {
 	p.validate(ctx, addr.WithNewQuality(types.Verifying, nil))
 }
 
@@ -220,14 +317,18 @@ func (p *Pinger) ValidateQA(ctx context.Context, addr types.QualifiedAddress) {
 // invalid. However, spurious verification verdicts might still appear on the
 // verdict stream due to uncontrollable order of verdict sending and context
 // cancellation detection.
-func (p *Pinger) validate(ctx context.Context, verdict types.QualifiedAddress) {
+//
+// The verdicts are converted into Qs only when sending them, as
+// [types.QualifiedAddress.WithNewQuality] returns the interface type.
+func (p *PingerOf[Q]) validate(ctx context.Context, verdict types.QualifiedAddress) {
 	// Allow cancelling a blocked address verdict send to avoid leaking
 	// goroutines. The downside is that since the order in which select checks
 	// for ctx.Done() and a blocked verdict channel is random, so we cannot
 	// guarantuee that either never a verdict is sent or the verdict gets always
 	// sent.
+	verdict = types.Stamp(verdict, types.NewProvenance(types.StagePinger, p.origin))
 	select {
-	case p.courtTV <- verdict: // not yet the final one ;)
+	case p.courtTV <- verdict.(Q): // not yet the final one ;)
 	case <-ctx.Done():
 		return
 	}
@@ -236,8 +337,9 @@ func (p *Pinger) validate(ctx context.Context, verdict types.QualifiedAddress) {
 		defer func() {
 			// Again, allow cancelling a blocked address verdict send to avoid
 			// leaking goroutines.
+			verdict = types.Stamp(verdict, types.NewProvenance(types.StagePinger, p.origin))
 			select {
-			case p.courtTV <- verdict: // final one this time.
+			case p.courtTV <- verdict.(Q): // final one this time.
 			case <-ctx.Done():
 				return
 			}
@@ -251,28 +353,22 @@ func (p *Pinger) validate(ctx context.Context, verdict types.QualifiedAddress) {
 			default:
 			}
 
-			pinger := ping.New(verdict.Addr()) // not! ping.NewPinger, would do an immediate resolve
-			pinger.SetPrivileged(!p.unprivileged)
-			pinger.Count = p.count
-			pinger.Interval = p.interval
-			// Always limit waiting for the last ping to get reflected (or not)!
-			timeout := time.Duration(int64(p.interval) * int64(p.count+2))
-			pinger.ResolveTimeout = timeout
-			pingctx, cancel := context.WithTimeout(ctx, timeout)
-			defer cancel()
-			// Now start making some noise...
-			if err := pinger.RunWithContext(pingctx); err != nil {
-				return err
+			var recv int
+			var rtt time.Duration
+			var err error
+			if p.tcpPort != 0 {
+				recv, rtt, err = p.probeTCP(ctx, verdict.Addr())
+			} else {
+				recv, rtt, err = p.ping(ctx, verdict.Addr())
 			}
-			// Was the overall validation context done?
-			if err := ctx.Err(); err != nil {
+			if err != nil {
 				return err
 			}
-			stats := pinger.Statistics()
-			if stats.PacketsRecv < pinger.Count*int(p.thresholdPercentage)/100 {
-				return errors.New("no replies or too many losses")
+			q, err := p.quality(recv, rtt)
+			verdict = verdict.WithNewQuality(q, nil)
+			if err != nil {
+				return err
 			}
-			verdict = verdict.WithNewQuality(types.Verified, nil)
 			return nil
 		}
 		// Run the ping in the requested network namespace, if necessary.
@@ -303,9 +399,88 @@ func (p *Pinger) validate(ctx context.Context, verdict types.QualifiedAddress) {
 	})
 }
 
+// quality returns the verdict for an address that answered the specified
+// number of the configured pings at the specified average round-trip time,
+// together with the reason for a verdict other than [types.Verified].
+func (p *pingerConfig) quality(recv int, rtt time.Duration) (types.Quality, error) {
+	switch {
+	case recv < p.count*int(p.thresholdPercentage)/100:
+		return types.Unreachable, types.NewAddressError(types.ErrorUnreachable,
+			errors.New("no replies or too many losses"))
+	case recv < p.count*int(p.softPercentage)/100:
+		return types.Degraded, types.NewAddressError(types.ErrorLosses,
+			fmt.Errorf("%d%% losses", 100-recv*100/p.count))
+	case p.maxRTT != 0 && rtt > p.maxRTT:
+		return types.Degraded, types.NewAddressError(types.ErrorLatency,
+			fmt.Errorf("average round-trip time %s exceeds %s", rtt, p.maxRTT))
+	}
+	return types.Verified, nil
+}
+
+// ping the specified address and return the number of ping replies received,
+// as well as the average round-trip time.
+func (p *pingerConfig) ping(ctx context.Context, addr string) (int, time.Duration, error) {
+	pinger := ping.New(addr) // not! ping.NewPinger, would do an immediate resolve
+	pinger.SetPrivileged(!p.unprivileged)
+	pinger.Count = p.count
+	pinger.Interval = p.interval
+	// Always limit waiting for the last ping to get reflected (or not)!
+	timeout := time.Duration(int64(p.interval) * int64(p.count+2))
+	pinger.ResolveTimeout = timeout
+	pingctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// Now start making some noise...
+	if err := pinger.RunWithContext(pingctx); err != nil {
+		return 0, 0, err
+	}
+	// Was the overall validation context done?
+	if err := ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	stats := pinger.Statistics()
+	return stats.PacketsRecv, stats.AvgRtt, nil
+}
+
+// probeTCP connects to the TCP port of the specified address for the
+// configured number of times and returns the number of successful connects, as
+// well as the average connect time.
+// Please note that probeTCP must be called on the OS-level thread already
+// switched into the correct network namespace, as it dials directly from this
+// thread.
+func (p *pingerConfig) probeTCP(ctx context.Context, addr string) (int, time.Duration, error) {
+	dialer := net.Dialer{Timeout: p.interval}
+	target := net.JoinHostPort(addr, strconv.FormatUint(uint64(p.tcpPort), 10))
+	recv := 0
+	var total time.Duration
+	for probe := 0; probe < p.count; probe++ {
+		if probe > 0 {
+			select {
+			case <-time.After(p.interval):
+			case <-ctx.Done():
+				return 0, 0, ctx.Err()
+			}
+		}
+		start := time.Now()
+		conn, err := dialer.DialContext(ctx, "tcp", target)
+		if err != nil {
+			if ctxerr := ctx.Err(); ctxerr != nil {
+				return 0, 0, ctxerr
+			}
+			continue
+		}
+		total += time.Since(start)
+		conn.Close()
+		recv++
+	}
+	if recv == 0 {
+		return 0, 0, nil
+	}
+	return recv, total / time.Duration(recv), nil
+}
+
 // StopWait waits for all queued tasks to get processed and then finally closes
 // the court TV channel.
-func (p *Pinger) StopWait() {
+func (p *PingerOf[Q]) StopWait() {
 	p.stopOnce.Do(func() {
 		p.workers.StopWait()
 		close(p.courtTV)
@@ -7,6 +7,7 @@ package ping
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"strconv"
 	"time"
@@ -92,13 +93,68 @@ var _ = Describe("pinger", Ordered, func() {
 			QualifiedAddressValue: types.QualifiedAddressValue{Address: "localhost"},
 		})
 		Eventually(courtTV).WithTimeout(5 * time.Second).Should(Receive(
-			HaveValue(Equal(types.NamedAddressValue{
-				FQDN: "foobar",
-				QualifiedAddressValue: types.QualifiedAddressValue{
-					Address: "localhost",
-					Quality: types.Verified,
-				},
-			}))))
+			HaveValue(And(
+				HaveField("Provenance.Stage", types.StagePinger),
+				WithTransform(namedWithoutProvenance, Equal(types.NamedAddressValue{
+					FQDN: "foobar",
+					QualifiedAddressValue: types.QualifiedAddressValue{
+						Address: "localhost",
+						Quality: types.Verified,
+					},
+				}))))))
+		pinger.StopWait()
+		Eventually(courtTV).Should(BeClosed())
+	})
+
+	It("passes payloads through", NodeTimeout(30*time.Second), func(ctx context.Context) {
+		type payload struct{ Owner string }
+		pinger, courtTV := NewOf[*types.NamedAddressWith[payload]](1)
+		pinger.ValidateQA(ctx, &types.NamedAddressWith[payload]{
+			NamedAddressValue: types.NamedAddressValue{
+				FQDN:                  "foobar",
+				QualifiedAddressValue: types.QualifiedAddressValue{Address: "localhost"},
+			},
+			Payload: payload{Owner: "team-foo"},
+		})
+		var verdict *types.NamedAddressWith[payload]
+		Eventually(courtTV).WithTimeout(5 * time.Second).Should(Receive(&verdict))
+		Expect(verdict.Qual()).To(Equal(types.Verifying))
+		Expect(verdict.Payload.Owner).To(Equal("team-foo"))
+		Eventually(courtTV).WithTimeout(5 * time.Second).Should(Receive(&verdict))
+		Expect(verdict.Qual()).To(Equal(types.Verified))
+		Expect(verdict.Payload.Owner).To(Equal("team-foo"))
+		Expect(verdict.Provenance).To(HaveField("Stage", types.StagePinger))
+		pinger.StopWait()
+		Eventually(courtTV).Should(BeClosed())
+	})
+
+	It("probes TCP ports", NodeTimeout(30*time.Second), func(ctx context.Context) {
+		l := Successful(net.Listen("tcp", "127.0.0.1:0"))
+		port := uint16(l.Addr().(*net.TCPAddr).Port)
+		l.Close() // ...rendering the port unused, if all goes well
+		pinger, courtTV := New(1,
+			WithTCPProbe(port),
+			WithCount(1),
+			WithInterval(100*time.Millisecond),
+			WithThresholdPercentage(100))
+		pinger.Validate(ctx, "127.0.0.1")
+		Eventually(courtTV).Should(Receive(HaveValue(HaveField("Quality", types.Verifying))))
+		Eventually(courtTV).Should(Receive(HaveValue(HaveField("Quality", types.Unreachable))))
+
+		l = Successful(net.Listen("tcp", l.Addr().String()))
+		defer l.Close()
+		go func() {
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				conn.Close()
+			}
+		}()
+		pinger.Validate(ctx, "127.0.0.1")
+		Eventually(courtTV).Should(Receive(HaveValue(HaveField("Quality", types.Verifying))))
+		Eventually(courtTV).Should(Receive(HaveValue(HaveField("Quality", types.Verified))))
 		pinger.StopWait()
 		Eventually(courtTV).Should(BeClosed())
 	})
@@ -210,16 +266,21 @@ var _ = Describe("pinger", Ordered, func() {
 			pinger.Validate(ctx, addr)
 			By("waiting for intermediate verification in-progress verdict")
 			Eventually(courtTV).WithTimeout(10 * time.Second).Should(Receive(
-				HaveValue(Equal(types.QualifiedAddressValue{
+				HaveValue(WithTransform(withoutProvenance, Equal(types.QualifiedAddressValue{
 					Address: addr,
 					Quality: types.Verifying,
-				}))))
+				})))))
 			By("waiting for final invalidation verdict")
-			Eventually(courtTV).WithTimeout(10*time.Second).Should(Receive(
-				HaveValue(Equal(types.QualifiedAddressValue{
-					Address: addr,
-					Quality: verdict,
-				}))), "waited for the train that never came: address should be %s", verdict)
+			var final types.QualifiedAddress
+			Eventually(courtTV).WithTimeout(10*time.Second).Should(Receive(&final),
+				"waited for the train that never came: address should be %s", verdict)
+			Expect(final.Addr()).To(Equal(addr))
+			Expect(final.Qual()).To(Equal(verdict))
+			if verdict == types.Invalid {
+				Expect(final.Err()).To(HaveOccurred(), "missing reason for invalid verdict")
+			} else {
+				Expect(final.Err()).NotTo(HaveOccurred())
+			}
 			pinger.StopWait()
 			Eventually(courtTV).Should(BeClosed())
 		},
@@ -228,3 +289,17 @@ var _ = Describe("pinger", Ordered, func() {
 	)
 
 })
+
+// withoutProvenance returns the specified qualified address without its
+// provenance information, so that it can be easily compared.
+func withoutProvenance(qa types.QualifiedAddressValue) types.QualifiedAddressValue {
+	qa.Provenance = nil
+	return qa
+}
+
+// namedWithoutProvenance returns the specified named address without its
+// provenance information, so that it can be easily compared.
+func namedWithoutProvenance(na types.NamedAddressValue) types.NamedAddressValue {
+	na.Provenance = nil
+	return na
+}
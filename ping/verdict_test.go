@@ -0,0 +1,51 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package ping
+
+import (
+	"time"
+
+	"github.com/siemens/mobydig/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("verdicts", func() {
+
+	DescribeTable("judges replies and round-trip times",
+		func(cfg pingerConfig, recv int, rtt time.Duration, q types.Quality, kind types.ErrorKind) {
+			quality, err := cfg.quality(recv, rtt)
+			Expect(quality).To(Equal(q))
+			if kind == "" {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			Expect(types.AsAddressError(err)).To(HaveField("Kind", kind))
+		},
+		Entry("all replies",
+			pingerConfig{count: 3, thresholdPercentage: 50}, 3, time.Millisecond,
+			types.Verified, types.ErrorKind("")),
+		Entry("losses without a soft threshold",
+			pingerConfig{count: 3, thresholdPercentage: 50}, 2, time.Millisecond,
+			types.Verified, types.ErrorKind("")),
+		Entry("losses below the soft threshold",
+			pingerConfig{count: 3, thresholdPercentage: 50, softPercentage: 100}, 2, time.Millisecond,
+			types.Degraded, types.ErrorLosses),
+		Entry("losses below the threshold",
+			pingerConfig{count: 4, thresholdPercentage: 50, softPercentage: 100}, 1, time.Millisecond,
+			types.Unreachable, types.ErrorUnreachable),
+		Entry("no replies",
+			pingerConfig{count: 3, thresholdPercentage: 50}, 0, time.Duration(0),
+			types.Unreachable, types.ErrorUnreachable),
+		Entry("round-trip times within maximum",
+			pingerConfig{count: 3, thresholdPercentage: 50, maxRTT: 10 * time.Millisecond}, 3, 10*time.Millisecond,
+			types.Verified, types.ErrorKind("")),
+		Entry("round-trip times above maximum",
+			pingerConfig{count: 3, thresholdPercentage: 50, maxRTT: 10 * time.Millisecond}, 3, 11*time.Millisecond,
+			types.Degraded, types.ErrorLatency),
+	)
+
+})
@@ -0,0 +1,22 @@
+/*
+Package pipeline runs the complete mobydig pipeline for a Docker container in a
+single call: discovering the networks attached to the container and the names
+on these networks, digging these names from the perspective of the container,
+and finally verifying the addresses dug by pinging them.
+
+[Run] returns a [Pipeline] streaming the named addresses as they pass through
+the pipeline, while [Pipeline.Report] waits for the pipeline to finish and then
+returns the [FinalReport]. Embedders wanting to render intermediate results,
+such as the mobydig CLI, can instead poll [Pipeline.Snapshot] and
+[Pipeline.Networks].
+
+The pipeline is configured using [Option]s, such as [WithWorkers],
+[WithDiscoveryOptions], [WithDiggerOptions], [WithPingerOptions] and
+[WithExternals].
+
+Cancelling the context passed to Run stops discovering, digging and verifying.
+The final report then still contains the results so far, with the names not
+completely dug and verified marked as incomplete, see also
+[dig.MarkIncomplete].
+*/
+package pipeline
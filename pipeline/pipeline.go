@@ -0,0 +1,384 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/siemens/mobydig/dig"
+	"github.com/siemens/mobydig/hostsfile"
+	"github.com/siemens/mobydig/mobynet"
+	"github.com/siemens/mobydig/ping"
+	"github.com/siemens/mobydig/types"
+	"github.com/siemens/mobydig/verifier"
+
+	"github.com/docker/docker/client"
+)
+
+// DefaultWorkers is the default number of DNS and ping workers.
+const DefaultWorkers = 5
+
+// Option configures a [Pipeline] when passed to [Run].
+type Option func(*config)
+
+// config is the configuration of a pipeline.
+type config struct {
+	moby         *client.Client
+	workers      int
+	discovery    []mobynet.DiscoveryOption
+	digger       []dig.DiggerOption
+	pinger       []ping.PingerOption
+	hosts        bool
+	externals    []string
+	externalPort uint16
+}
+
+// WithDockerClient uses the specified Docker client instead of connecting to
+// the Docker daemon at its standard socket. The pipeline never closes the
+// specified client.
+func WithDockerClient(moby *client.Client) Option {
+	return func(c *config) {
+		c.moby = moby
+	}
+}
+
+// WithWorkers sets the number of DNS and ping workers, defaulting to
+// [DefaultWorkers].
+func WithWorkers(n int) Option {
+	return func(c *config) {
+		c.workers = n
+	}
+}
+
+// WithDiscoveryOptions filters and tunes the discovery of networks, containers
+// and names, such as [mobynet.WithNetworks] and [mobynet.WithConcurrency].
+func WithDiscoveryOptions(opts ...mobynet.DiscoveryOption) Option {
+	return func(c *config) {
+		c.discovery = append(c.discovery, opts...)
+	}
+}
+
+// WithDiggerOptions configures the digging of names, such as
+// [dig.WithNameMode] and [dig.WithReverseLookups].
+func WithDiggerOptions(opts ...dig.DiggerOption) Option {
+	return func(c *config) {
+		c.digger = append(c.digger, opts...)
+	}
+}
+
+// WithPingerOptions configures the verification of addresses, such as
+// [ping.WithMaxRTT].
+func WithPingerOptions(opts ...ping.PingerOption) Option {
+	return func(c *config) {
+		c.pinger = append(c.pinger, opts...)
+	}
+}
+
+// WithoutHosts skips verifying the names from the /etc/hosts of the container,
+// which are verified by default, see also [dig.Digger.DigHosts].
+func WithoutHosts() Option {
+	return func(c *config) {
+		c.hosts = false
+	}
+}
+
+// WithExternals additionally digs and verifies the specified external names,
+// such as registry host names.
+func WithExternals(names ...string) Option {
+	return func(c *config) {
+		c.externals = append(c.externals, names...)
+	}
+}
+
+// WithExternalTCPProbe verifies the addresses of external names by probing the
+// specified TCP port instead of pinging them, see also [ping.WithTCPProbe].
+func WithExternalTCPProbe(port uint16) Option {
+	return func(c *config) {
+		c.externalPort = port
+	}
+}
+
+// Pipeline is a running discover-dig-verify pipeline for a particular
+// container, as returned by [Run].
+type Pipeline struct {
+	// Results streams the named addresses as they pass through the pipeline,
+	// after they have been recorded in the pipeline's map of named addresses.
+	// Results gets closed when the pipeline has finished.
+	Results <-chan types.NamedAddress
+
+	center   string
+	namaddrs *dig.NamedAddressesMap
+	mu       sync.Mutex          // protects networks.
+	networks []dig.DockerNetwork // attached networks discovered so far.
+	done     chan struct{}       // closed after report has been set.
+	report   *FinalReport
+}
+
+// FinalReport is the final report of a pipeline.
+type FinalReport struct {
+	Center   string                // name or ID of the center container.
+	Networks []dig.DockerNetwork   // networks attached to the center container.
+	Names    []dig.NamedAddressSet // named address sets, incomplete ones marked.
+	// why the results are incomplete, such as "interrupted" or "timed out",
+	// or "" if they are complete.
+	Incomplete string
+	// error encountered while discovering the attached networks, if any,
+	// regardless of whether the results are incomplete.
+	Err error
+}
+
+// Run starts the discover-dig-verify pipeline for the specified center
+// container, configured using the specified options, and then immediately
+// returns the running pipeline.
+//
+// Run returns an error if the Docker daemon cannot be connected to, or the
+// center container cannot be inspected or isn't suitable for digging, such as
+// when not running or using the host network. Errors during the later
+// discovery of the attached networks are reported in [FinalReport.Err] instead.
+//
+// Callers must either receive from the Results channel until it gets closed,
+// or call [Pipeline.Report], as otherwise the pipeline stalls.
+func Run(ctx context.Context, center string, opts ...Option) (*Pipeline, error) {
+	c := config{
+		workers: DefaultWorkers,
+		hosts:   true,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.workers < 1 {
+		c.workers = 1
+	}
+	moby := c.moby
+	if moby == nil {
+		var err error
+		moby, err = client.NewClientWithOpts(
+			client.WithHost("unix:///var/run/docker.sock"),
+			client.WithAPIVersionNegotiation(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot connect to the Docker daemon: %w", err)
+		}
+	}
+	closeMoby := func() {
+		if c.moby == nil {
+			moby.Close()
+		}
+	}
+
+	// Start discovering the attached networks and their containers; the
+	// networks then get streamed as they are discovered, so that digging can
+	// already start before the discovery has finished.
+	attachedNets, err := mobynet.StreamAttachedNames(ctx, moby, center, c.discovery...)
+	if err != nil {
+		closeMoby()
+		return nil, fmt.Errorf("cannot discover attached networks and their containers: %w", err)
+	}
+	netnsref := attachedNets.Netnsref
+	var centerHosts *hostsfile.Hosts
+	var centerHostnames []string
+	if c.hosts {
+		var hostsFile string
+		hostsFile, centerHostnames, err = mobynet.CenterHostsFile(ctx, moby, center)
+		if err == nil {
+			centerHosts, err = hostsfile.ReadFile(hostsFile)
+		}
+		if err != nil {
+			go drainNetworks(attachedNets)
+			closeMoby()
+			return nil, fmt.Errorf("cannot read /etc/hosts of container: %w", err)
+		}
+	}
+
+	// Now lets put the required processing elements and their plumbing in
+	// place.
+	//
+	//   - Digger producing IP addresses from the names on the networks.
+	//   - Verifier consuming the IPs and checking them, producing "verdicts".
+	//   - NamedAddressMap consuming these "verdicts".
+	//
+	// External names get their own Digger and Verifier, as they might need to
+	// be probed differently from container and service names.
+	digger, diggernews, err := dig.New(c.workers, netnsref, c.digger...)
+	if err != nil {
+		go drainNetworks(attachedNets)
+		closeMoby()
+		return nil, fmt.Errorf("cannot dig address information: %w", err)
+	}
+	var extdigger *dig.Digger
+	var extdiggernews chan types.NamedAddress
+	if len(c.externals) != 0 {
+		extdigger, extdiggernews, err = dig.New(c.workers, netnsref, c.digger...)
+		if err != nil {
+			digger.StopWait()
+			go drainNetworks(attachedNets)
+			closeMoby()
+			return nil, fmt.Errorf("cannot dig address information: %w", err)
+		}
+	}
+
+	results := make(chan types.NamedAddress)
+	p := &Pipeline{
+		Results:  results,
+		center:   center,
+		namaddrs: dig.NewNamedAddressesMap(),
+		done:     make(chan struct{}),
+	}
+
+	vf, news := verifier.New(c.workers, netnsref, c.pinger...)
+	go vf.Verify(ctx, diggernews)
+	var tracking sync.WaitGroup
+	tracking.Add(1)
+	go func() {
+		defer tracking.Done()
+		p.track(ctx, news, results)
+	}()
+	if extdigger != nil {
+		extpingeropts := c.pinger
+		if c.externalPort != 0 {
+			extpingeropts = append(slices.Clip(extpingeropts), ping.WithTCPProbe(c.externalPort))
+		}
+		extvf, extnews := verifier.New(c.workers, netnsref, extpingeropts...)
+		go extvf.Verify(ctx, extdiggernews)
+		tracking.Add(1)
+		go func() {
+			defer tracking.Done()
+			p.track(ctx, extnews, results)
+		}()
+		extnames := make(chan dig.Diggable)
+		go extdigger.DigStream(ctx, extnames)
+		go func() {
+			defer close(extnames)
+			for _, name := range c.externals {
+				extnames <- dig.FQDNDiggable(name)
+			}
+		}()
+	}
+
+	// Finally feed the information about attached networks and their names as
+	// they get discovered, as well as the names from the container's
+	// /etc/hosts, into the Digger, so they can be processed and move through
+	// the different stages.
+	diggables := make(chan dig.Diggable)
+	go digger.DigStream(ctx, diggables)
+	go func() {
+		defer close(diggables)
+		for dnet := range attachedNets.Networks {
+			p.mu.Lock()
+			p.networks = append(p.networks, dnet)
+			p.mu.Unlock()
+			diggables <- dig.NetworkDiggable(dnet)
+		}
+		if centerHosts != nil {
+			diggables <- dig.HostsDiggable(centerHosts, centerHostnames...)
+		}
+	}()
+
+	// After all data has passed the stages, or the context has been
+	// cancelled, wrap up with the final report.
+	go func() {
+		tracking.Wait()
+		close(results)
+		closeMoby()
+		report := &FinalReport{
+			Center:   center,
+			Networks: p.Networks(),
+			Names:    p.Snapshot(),
+		}
+		if ctx.Err() != nil {
+			report.Incomplete = IncompleteReason(ctx.Err())
+			dig.MarkIncomplete(report.Names, report.Incomplete)
+		}
+		// Discovery errors are reported even when the results are incomplete
+		// anyway, except for those caused by the cancellation itself.
+		if err := attachedNets.Err(); err != nil && !isContextErr(err) {
+			report.Err = err
+		}
+		p.report = report
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// track records the named addresses received from the specified channel until
+// the channel is closed or the context done, passing them on to the results
+// channel.
+func (p *Pipeline) track(ctx context.Context, news <-chan types.NamedAddress, results chan<- types.NamedAddress) {
+	for {
+		select {
+		case namaddr, ok := <-news:
+			if !ok {
+				return
+			}
+			p.namaddrs.Update(namaddr)
+			select {
+			case results <- namaddr:
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// drainNetworks discards the networks of the specified stream until it gets
+// closed, so that the discovery never blocks.
+func drainNetworks(stream *mobynet.NetworkStream) {
+	for range stream.Networks {
+	}
+}
+
+// isContextErr returns true if the specified error has been caused by a
+// cancelled context or an exceeded deadline.
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// IncompleteReason returns why a pipeline didn't complete, based on the
+// specified error of its cancelled context.
+func IncompleteReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timed out"
+	}
+	return "interrupted"
+}
+
+// Center returns the name or ID of the pipeline's center container.
+func (p *Pipeline) Center() string {
+	return p.center
+}
+
+// Networks returns the networks attached to the center container that have
+// been discovered so far.
+func (p *Pipeline) Networks() []dig.DockerNetwork {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.networks)
+}
+
+// Snapshot returns (a copy of) the named address sets so far.
+func (p *Pipeline) Snapshot() []dig.NamedAddressSet {
+	return p.namaddrs.Get()
+}
+
+// Done returns a channel that gets closed when the pipeline has finished and
+// its final report is available.
+func (p *Pipeline) Done() <-chan struct{} {
+	return p.done
+}
+
+// Report waits for the pipeline to finish and then returns its final report.
+// Any results not yet received from the Results channel get discarded.
+func (p *Pipeline) Report() *FinalReport {
+	for range p.Results {
+	}
+	<-p.done
+	return p.report
+}
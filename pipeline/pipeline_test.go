@@ -0,0 +1,109 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/siemens/mobydig/dig"
+	"github.com/siemens/mobydig/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("discover-dig-verify pipeline", func() {
+
+	BeforeEach(func() {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(3 * time.Second).ProbeEvery(250 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	DescribeTable("explains incomplete results",
+		func(err error, expected string) {
+			Expect(IncompleteReason(err)).To(Equal(expected))
+		},
+		Entry("when cancelled", context.Canceled, "interrupted"),
+		Entry("when timed out", context.DeadlineExceeded, "timed out"),
+		Entry("when wrapped", errors.Join(errors.New("foo"), context.DeadlineExceeded), "timed out"),
+	)
+
+	DescribeTable("tells cancellations from discovery errors",
+		func(err error, expected bool) {
+			Expect(isContextErr(err)).To(Equal(expected))
+		},
+		Entry("when cancelled", context.Canceled, true),
+		Entry("when timed out", fmt.Errorf("inspecting network: %w", context.DeadlineExceeded), true),
+		Entry("when failing", errors.New("no such network"), false),
+	)
+
+	When("running against containers", func() {
+
+		BeforeEach(func() {
+			if os.Getuid() != 0 {
+				Skip("needs root")
+			}
+		})
+
+		It("discovers, digs and verifies", NodeTimeout(30*time.Second), func(ctx context.Context) {
+			p := Successful(Run(ctx, "test-test-1",
+				WithWorkers(4),
+				WithDiggerOptions(dig.WithNameMode(dig.QualifiedNamesOnly))))
+			Expect(p.Center()).To(Equal("test-test-1"))
+
+			By("streaming results")
+			Eventually(p.Results).WithContext(ctx).Should(Receive(
+				HaveValue(HaveField("QualifiedAddressValue.Quality", types.Verified))))
+
+			By("waiting for the final report")
+			report := p.Report()
+			Expect(p.Done()).To(BeClosed())
+			Expect(report.Err).NotTo(HaveOccurred())
+			Expect(report.Incomplete).To(BeEmpty())
+			Expect(report.Networks).To(ContainElement(HaveField("Label", "net_A")))
+			Expect(report.Names).To(ContainElement(And(
+				HaveField("FQDN", "foo.net_A."),
+				HaveField("Addresses", HaveEach(HaveField("Quality", types.Verified))),
+			)))
+		})
+
+		It("marks partial results as incomplete", NodeTimeout(30*time.Second), func(ctx context.Context) {
+			ctx, cancel := context.WithCancel(ctx)
+			p := Successful(Run(ctx, "test-test-1"))
+
+			By("interrupting while an address is still pending")
+			Eventually(p.Results).WithContext(ctx).Should(Receive(HaveValue(And(
+				HaveField("QualifiedAddressValue.Address", Not(BeEmpty())),
+				HaveField("QualifiedAddressValue.Quality", Satisfy(types.Quality.IsPending)),
+			))))
+			cancel()
+			report := p.Report()
+			Expect(report.Incomplete).To(Equal("interrupted"))
+			for _, set := range report.Names {
+				pending := len(set.Addresses) == 0
+				for _, addr := range set.Addresses {
+					pending = pending || addr.Quality.IsPending()
+				}
+				Expect(set.Has(dig.WarningIncomplete)).To(Equal(pending),
+					"name %s with addresses %v", set.FQDN, set.Addresses)
+			}
+		})
+
+		It("rejects unknown containers", NodeTimeout(30*time.Second), func(ctx context.Context) {
+			Expect(Run(ctx, "test-nonexisting-1")).Error().To(HaveOccurred())
+		})
+
+	})
+
+})
@@ -4,6 +4,8 @@
 
 package types
 
+import "time"
+
 // NamedAddress represents an FQDN or name, together with an IP address and the
 // quality (verification status, [Quality] type) of the address.
 type NamedAddress interface {
@@ -14,21 +16,34 @@ type NamedAddress interface {
 
 // QualifiedAddress gives access to qualified address information and also
 // allows updating the quality information aspect of an address.
+//
+// WithNewQuality never modifies the qualified address it is called on, but
+// instead returns an updated copy with the quality set to q and the error set
+// to err. The error always replaces any previous error, so passing a nil err
+// clears a previous error. WithNewQuality doesn't check quality transitions;
+// please see [Transition] and [Transit] instead.
 type QualifiedAddress interface {
 	Addr() string                                         // returns address
 	Qual() Quality                                        // returns Quality
-	Err() error                                           // if Quality is Invalid, optional additional error information.
+	Err() error                                           // optional error information explaining the Quality.
 	QA() QualifiedAddressValue                            // returns (a copy of) the qualified address information
 	WithNewQuality(q Quality, err error) QualifiedAddress // returns a new and updated qualified address
 }
 
 // NamedAddressValue implements a concrete representation of a [NamedAddress].
 type NamedAddressValue struct {
-	FQDN                  string `json:"fqdn"` // the DNS "name"
-	QualifiedAddressValue        // a single associated (resolved) IP network address
+	FQDN                  string        `json:"fqdn"`                 // the DNS "name"
+	CNAMEs                []string      `json:"cnames,omitempty"`     // optional CNAME chain that led from the FQDN to the address
+	Latency               time.Duration `json:"latency_ns,omitempty"` // optional total time of the A and AAAA lookups of the FQDN
+	Membership            *Membership   `json:"membership,omitempty"` // optional Docker network identity of the FQDN
+	Shadows               []string      `json:"shadows,omitempty"`    // optional addresses DNS resolves the FQDN to, when shadowed by /etc/hosts
+	QualifiedAddressValue               // a single associated (resolved) IP network address
 }
 
-var _ NamedAddress = (*NamedAddressValue)(nil)
+var (
+	_ NamedAddress = (*NamedAddressValue)(nil)
+	_ Stamper      = (*NamedAddressValue)(nil)
+)
 
 // Name returns the FQDN. Thank you, Go, for nothing.
 func (na *NamedAddressValue) Name() string {
@@ -40,26 +55,37 @@ func (na *NamedAddressValue) NA() NamedAddressValue {
 	return *na
 }
 
-// WithNewQuality returns newly qualified (named) address information.
+// WithNewQuality returns newly qualified (named) address information with the
+// specified quality and error, replacing any previous error.
 func (na *NamedAddressValue) WithNewQuality(q Quality, err error) QualifiedAddress {
-	qa := na.QA()
-	qa.Quality = q
-	qa.err = err
-	return &NamedAddressValue{
-		FQDN:                  na.FQDN,
-		QualifiedAddressValue: qa,
-	}
+	nna := *na
+	nna.Quality = q
+	nna.err = err
+	return &nna
 }
 
 // QualifiedAddressValue is a network address with an associated quality, such
 // as verified, verifying, verified, and invalid.
 type QualifiedAddressValue struct {
-	Address string  `json:"address"` // a single network IP (v4/v6) address
-	Quality Quality `json:"quality"` // quality (validation) state
-	err     error   // optional error details for invalid addresses
+	Address string   `json:"address"`        // a single network IP (v4/v6) address
+	Quality Quality  `json:"quality"`        // quality (validation) state
+	TTL     uint32   `json:"ttl,omitempty"`  // optional TTL in seconds of the DNS RR the address was taken from
+	PTRs    []string `json:"ptrs,omitempty"` // optional names from a reverse (PTR) lookup of the address
+	// optional container the address belongs to.
+	Container *ContainerInfo `json:"container,omitempty"`
+	// optional Docker network the address belongs to, for names not qualified
+	// with a network name.
+	Network string `json:"network,omitempty"`
+	// optional information about when and by which stage this address
+	// information was produced.
+	Provenance *Provenance `json:"provenance,omitempty"`
+	err        error       // optional error details explaining the quality
 }
 
-var _ QualifiedAddress = (*QualifiedAddressValue)(nil)
+var (
+	_ QualifiedAddress = (*QualifiedAddressValue)(nil)
+	_ Stamper          = (*QualifiedAddressValue)(nil)
+)
 
 // Addr returns the address.
 func (qa *QualifiedAddressValue) Addr() string { return qa.Address }
@@ -76,11 +102,11 @@ func (qa *QualifiedAddressValue) QA() QualifiedAddressValue {
 	return *qa
 }
 
-// WithNewQuality returns newly qualified address information.
+// WithNewQuality returns newly qualified address information with the specified
+// quality and error, replacing any previous error.
 func (qa *QualifiedAddressValue) WithNewQuality(q Quality, err error) QualifiedAddress {
-	return &QualifiedAddressValue{
-		Address: qa.Address,
-		Quality: q,
-		err:     qa.err,
-	}
+	nqa := *qa
+	nqa.Quality = q
+	nqa.err = err
+	return &nqa
 }
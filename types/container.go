@@ -0,0 +1,14 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package types
+
+// ContainerInfo identifies the container an address belongs to.
+type ContainerInfo struct {
+	ID      string `json:"id"`                // container ID.
+	Name    string `json:"name"`              // container name, without any leading "/".
+	Project string `json:"project,omitempty"` // Docker compose project, if any.
+	Service string `json:"service,omitempty"` // Docker compose service, if any.
+	Image   string `json:"image,omitempty"`   // image reference the container was created from.
+}
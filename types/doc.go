@@ -18,6 +18,52 @@ embedded QualifiedAddressValue.WithNewQuality method to be propagated to the new
 type, yet it won't return the proper new type, but instead only a stock
 QualifiedAddressValue, loosing the additional information in the process.
 
+Instead of embedding, applications preferably use [QualifiedAddressWith] or
+[NamedAddressWith] to carry an application-specific payload of their choice
+alongside the address information. These generic types correctly implement
+WithNewQuality, [Stamper], and JSON marshalling, so payloads safely travel
+through [github.com/siemens/mobydig/ping.PingerOf] and
+[github.com/siemens/mobydig/verifier.VerifierOf] pipelines without any type
+assertions on the application's side.
+
+Similarly, [QualifiedAddressValue] implements JSON marshalling in order to
+include its otherwise unexported error information in form of an
+[AddressError]. Types embedding QualifiedAddressValue thus need to implement
+their own JSON marshalling, as [NamedAddressValue] does, as otherwise only the
+embedded QualifiedAddressValue gets marshalled.
+
+# Quality Transitions
+
+Addresses start as [Unverified], then become [Verifying], and finally end up
+with one of the verdicts [Invalid], [Unreachable], [Degraded], or [Verified].
+[Transition] is the single place defining which quality changes are legal:
+pending qualities only ever move forward in the following order, possibly
+skipping qualities, and never fall back, so verdicts in particular never fall
+back to pending qualities, such as from Verified to Verifying. A verdict may
+change into any other verdict, though, such as from Verified to Unreachable
+when an address stops answering. Stages merging address updates, such as
+caches and maps, ignore updates with illegal transitions as stale.
+
+	Unverified --> Verifying --> Invalid | Unreachable | Degraded | Verified
+
+[QualifiedAddress.WithNewQuality] always sets both the quality and the error,
+so the error of an address always explains its current quality, if at all.
+
+# Provenance
+
+Addresses optionally carry their [Provenance], that is, when and by which
+[Stage] they were produced, as well as from where they were observed. Stages
+record provenance using [Stamp], which works with any [QualifiedAddress]
+implementation also implementing [Stamper], and otherwise leaves addresses
+untouched.
+
+# JSON
+
+Qualified and named addresses round-trip through JSON, including the reason an
+address is invalid: [Quality] values marshal as their clear-text
+representations, and errors as [AddressError] objects consisting of an
+[ErrorKind] and the error message.
+
 # Design Rationale
 
 The seemingly peculiar separation into a [QualifiedAddress] interface as well as
@@ -37,9 +83,12 @@ given only embedding gets tricky when passing things around through different
 layers.
 
 And no, “any”/“interface{}” doesn't appear to be a sensible architectural option
-here. Unfortunately, Generics don't seem to be able to improve this situation
-either and appear to be extremely heavy handed. On the other hand, if there is
-an idea and preferably a PR let's check and then do it.
+here. Generics don't remove the interface/struct split either, but they at
+least allow pipeline stages to be parameterized with the concrete address type
+flowing through them, such as in “PingerOf[*NamedAddressWith[T]]”. The
+non-generic Pinger and Verifier are then simply the instantiations for the
+[QualifiedAddress] and [NamedAddress] interface types; Pinger only wraps its
+instantiation in order to additionally validate plain address strings.
 
 Please keep in mind that mobydig is inherently concurrent wherever possible:
 digging multiple names and pinging (validation) lots of addresses can be carried
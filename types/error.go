@@ -0,0 +1,84 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package types
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// ErrorKind classifies the reason why an address failed verification, such as
+// a timeout or an unresolvable name.
+type ErrorKind string
+
+// The kinds of address verification errors.
+const (
+	ErrorGeneric     ErrorKind = "error"       // any other error.
+	ErrorCanceled    ErrorKind = "canceled"    // verification was cancelled.
+	ErrorTimeout     ErrorKind = "timeout"     // verification timed out.
+	ErrorDNS         ErrorKind = "dns"         // name could not be resolved.
+	ErrorNetwork     ErrorKind = "network"     // network operation failed.
+	ErrorUnreachable ErrorKind = "unreachable" // no replies or too many losses.
+	ErrorLosses      ErrorKind = "losses"      // losses above the soft threshold.
+	ErrorLatency     ErrorKind = "latency"     // round-trip times too high.
+)
+
+// AddressError describes why an address failed verification in terms of an
+// error kind and message, so that it survives JSON round trips. When created
+// from another error using [NewAddressError] it additionally wraps the original
+// error.
+type AddressError struct {
+	Kind    ErrorKind `json:"kind"`    // kind of error
+	Message string    `json:"message"` // error message
+	err     error     // optional original error
+}
+
+var _ error = (*AddressError)(nil)
+
+// NewAddressError returns a new AddressError of the specified kind, wrapping
+// the specified error.
+func NewAddressError(kind ErrorKind, err error) *AddressError {
+	return &AddressError{
+		Kind:    kind,
+		Message: err.Error(),
+		err:     err,
+	}
+}
+
+// Error returns the error message.
+func (e *AddressError) Error() string { return e.Message }
+
+// Unwrap returns the original error, if any.
+func (e *AddressError) Unwrap() error { return e.err }
+
+// AsAddressError returns the specified error as an AddressError. If err isn't
+// already an AddressError, then AsAddressError classifies err and wraps it
+// into a new AddressError. AsAddressError returns nil if err is nil.
+func AsAddressError(err error) *AddressError {
+	if err == nil {
+		return nil
+	}
+	var aerr *AddressError
+	if errors.As(err, &aerr) {
+		return aerr
+	}
+	var neterr net.Error
+	var dnserr *net.DNSError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return NewAddressError(ErrorCanceled, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return NewAddressError(ErrorTimeout, err)
+	case errors.As(err, &dnserr):
+		return NewAddressError(ErrorDNS, err)
+	case errors.As(err, &neterr):
+		if neterr.Timeout() {
+			return NewAddressError(ErrorTimeout, err)
+		}
+		return NewAddressError(ErrorNetwork, err)
+	}
+	return NewAddressError(ErrorGeneric, err)
+}
@@ -0,0 +1,98 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// plainQualifiedAddressValue has the same fields as QualifiedAddressValue, but
+// none of its methods, so that it can be (un)marshalled using the default JSON
+// (un)marshalling without ending in endless recursion.
+type plainQualifiedAddressValue QualifiedAddressValue
+
+// qualifiedAddressJSON is the JSON representation of a QualifiedAddressValue,
+// including its optional error information.
+type qualifiedAddressJSON struct {
+	plainQualifiedAddressValue
+	Error *AddressError `json:"error,omitempty"`
+}
+
+// namedAddressJSON is the JSON representation of a NamedAddressValue. As
+// NamedAddressValue embeds QualifiedAddressValue, it would otherwise get the
+// (promoted) JSON marshalling of only its QualifiedAddressValue part.
+type namedAddressJSON struct {
+	FQDN       string        `json:"fqdn"`
+	CNAMEs     []string      `json:"cnames,omitempty"`
+	Latency    time.Duration `json:"latency_ns,omitempty"`
+	Membership *Membership   `json:"membership,omitempty"`
+	Shadows    []string      `json:"shadows,omitempty"`
+	qualifiedAddressJSON
+}
+
+func newQualifiedAddressJSON(qa *QualifiedAddressValue) qualifiedAddressJSON {
+	return qualifiedAddressJSON{
+		plainQualifiedAddressValue: plainQualifiedAddressValue(*qa),
+		Error:                      AsAddressError(qa.err),
+	}
+}
+
+func (j *qualifiedAddressJSON) value() QualifiedAddressValue {
+	qa := QualifiedAddressValue(j.plainQualifiedAddressValue)
+	qa.err = nil
+	if j.Error != nil {
+		qa.err = j.Error
+	}
+	return qa
+}
+
+// MarshalJSON returns the JSON representation of the qualified address,
+// including any error information in form of an [AddressError].
+func (qa QualifiedAddressValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newQualifiedAddressJSON(&qa))
+}
+
+// UnmarshalJSON sets the qualified address from its JSON representation,
+// restoring any error information as an [*AddressError].
+func (qa *QualifiedAddressValue) UnmarshalJSON(data []byte) error {
+	var j qualifiedAddressJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*qa = j.value()
+	return nil
+}
+
+// MarshalJSON returns the JSON representation of the named address, including
+// any error information in form of an [AddressError].
+func (na NamedAddressValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(namedAddressJSON{
+		FQDN:                 na.FQDN,
+		CNAMEs:               na.CNAMEs,
+		Latency:              na.Latency,
+		Membership:           na.Membership,
+		Shadows:              na.Shadows,
+		qualifiedAddressJSON: newQualifiedAddressJSON(&na.QualifiedAddressValue),
+	})
+}
+
+// UnmarshalJSON sets the named address from its JSON representation, restoring
+// any error information as an [*AddressError].
+func (na *NamedAddressValue) UnmarshalJSON(data []byte) error {
+	var j namedAddressJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*na = NamedAddressValue{
+		FQDN:                  j.FQDN,
+		CNAMEs:                j.CNAMEs,
+		Latency:               j.Latency,
+		Membership:            j.Membership,
+		Shadows:               j.Shadows,
+		QualifiedAddressValue: j.value(),
+	}
+	return nil
+}
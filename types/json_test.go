@@ -0,0 +1,122 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("JSON", func() {
+
+	DescribeTable("(un)marshals qualities as text",
+		func(q Quality, text string) {
+			Expect(json.Marshal(q)).To(MatchJSON(`"` + text + `"`))
+			var q2 Quality
+			Expect(json.Unmarshal([]byte(`"`+text+`"`), &q2)).To(Succeed())
+			Expect(q2).To(Equal(q))
+		},
+		Entry(nil, Unverified, "unverified"),
+		Entry(nil, Verifying, "verifying"),
+		Entry(nil, Invalid, "invalid"),
+		Entry(nil, Verified, "verified"),
+		Entry(nil, Degraded, "degraded"),
+		Entry(nil, Unreachable, "unreachable"),
+	)
+
+	It("unmarshals legacy numeric qualities", func() {
+		var q Quality
+		Expect(json.Unmarshal([]byte(`3`), &q)).To(Succeed())
+		Expect(q).To(Equal(Verified))
+		Expect(json.Unmarshal([]byte(`42`), &q)).NotTo(Succeed())
+		Expect(json.Unmarshal([]byte(`"foobar"`), &q)).NotTo(Succeed())
+		Expect(json.Marshal(Quality(42))).Error().To(HaveOccurred())
+	})
+
+	DescribeTable("classifies errors",
+		func(err error, kind ErrorKind) {
+			aerr := AsAddressError(err)
+			Expect(aerr.Kind).To(Equal(kind))
+			Expect(aerr.Message).To(Equal(err.Error()))
+			Expect(errors.Is(aerr, err)).To(BeTrue())
+		},
+		Entry(nil, errors.New("D'oh!"), ErrorGeneric),
+		Entry(nil, context.Canceled, ErrorCanceled),
+		Entry(nil, context.DeadlineExceeded, ErrorTimeout),
+		Entry(nil, &net.DNSError{Err: "no such host", Name: "foo"}, ErrorDNS),
+		Entry(nil, &net.OpError{Op: "dial", Err: errors.New("refused")}, ErrorNetwork),
+	)
+
+	It("round-trips named addresses including errors", func() {
+		na := NamedAddressValue{
+			FQDN:    "foo.net_A.",
+			CNAMEs:  []string{"bar.net_A."},
+			Latency: 42 * time.Millisecond,
+			Membership: &Membership{
+				Network: "net_A",
+				Label:   "foo",
+				Alias:   true,
+			},
+			Shadows: []string{"172.24.0.3"},
+			QualifiedAddressValue: QualifiedAddressValue{
+				Address: "172.24.0.2",
+				Quality: Unreachable,
+				TTL:     600,
+				PTRs:    []string{"test-foo-1.net_A."},
+				Container: &ContainerInfo{
+					ID:      "1234",
+					Name:    "test-foo-1",
+					Project: "test",
+					Service: "foo",
+					Image:   "busybox:latest",
+				},
+				err: NewAddressError(ErrorUnreachable, errors.New("no replies")),
+			},
+		}
+		j := Successful(json.Marshal(na))
+		Expect(j).To(MatchJSON(`{
+			"fqdn": "foo.net_A.",
+			"cnames": ["bar.net_A."],
+			"latency_ns": 42000000,
+			"membership": {"network": "net_A", "label": "foo", "alias": true},
+			"shadows": ["172.24.0.3"],
+			"address": "172.24.0.2",
+			"quality": "unreachable",
+			"ttl": 600,
+			"ptrs": ["test-foo-1.net_A."],
+			"container": {
+				"id": "1234",
+				"name": "test-foo-1",
+				"project": "test",
+				"service": "foo",
+				"image": "busybox:latest"
+			},
+			"error": {"kind": "unreachable", "message": "no replies"}
+		}`))
+		var na2 NamedAddressValue
+		Expect(json.Unmarshal(j, &na2)).To(Succeed())
+		Expect(na2.Err()).To(MatchError("no replies"))
+		Expect(na2.Err()).To(HaveField("Kind", ErrorUnreachable))
+		Expect(json.Marshal(na2)).To(MatchJSON(j))
+	})
+
+	It("round-trips qualified addresses without errors", func() {
+		qas := []QualifiedAddressValue{{Address: "172.24.0.2", Quality: Verified}}
+		j := Successful(json.Marshal(qas))
+		Expect(j).To(MatchJSON(`[{"address": "172.24.0.2", "quality": "verified"}]`))
+		var qas2 []QualifiedAddressValue
+		Expect(json.Unmarshal(j, &qas2)).To(Succeed())
+		Expect(qas2).To(Equal(qas))
+		Expect(qas2[0].Err()).To(BeNil())
+	})
+
+})
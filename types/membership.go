@@ -0,0 +1,41 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package types
+
+// Membership describes what a DNS name refers to in terms of Docker networks:
+// the DNS label, such as a container name or alias, as well as the Docker
+// network the name is qualified with, if any. Memberships thus keep the
+// structured identity of names which otherwise would need to be parsed from the
+// names themselves, which is ambiguous as Docker network and container names
+// might contain dots.
+type Membership struct {
+	Network   string `json:"network,omitempty"`   // name of the Docker network, or "" for a bare label.
+	NetworkID string `json:"networkid,omitempty"` // ID of the Docker network, if known.
+	Label     string `json:"label"`               // container name or alias used as DNS label.
+	Alias     bool   `json:"alias,omitempty"`     // true if Label is an alias instead of a container name.
+	Link      bool   `json:"link,omitempty"`      // true if Label is a legacy link name, resolving only via /etc/hosts.
+	Hosts     bool   `json:"hosts,omitempty"`     // true if Label is a name from the center's /etc/hosts.
+	// for bare labels, the names of the Docker networks the label appears on.
+	Networks []string `json:"networks,omitempty"`
+}
+
+// Ambiguous returns true if this is a bare label appearing on multiple Docker
+// networks, so that Docker's embedded DNS resolver might answer the bare name
+// with addresses from either network. Only digging the name tells whether
+// these addresses actually belong to different containers or services.
+func (m *Membership) Ambiguous() bool {
+	return m.Network == "" && len(m.Networks) > 1
+}
+
+// Name returns the (not fully qualified) DNS name of the membership, that is,
+// the label either qualified with the Docker network name or just the bare
+// label. Legacy link names and /etc/hosts names are never qualified with the
+// network name.
+func (m *Membership) Name() string {
+	if m.Network == "" || m.Link || m.Hosts {
+		return m.Label
+	}
+	return m.Label + "." + m.Network
+}
@@ -0,0 +1,134 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// QualifiedAddressWith is a qualified address carrying an application-specific
+// payload of type T alongside. In contrast to embedding [QualifiedAddressValue]
+// into an application-specific type, QualifiedAddressWith correctly implements
+// [QualifiedAddress.WithNewQuality], [Stamper] and JSON marshalling, so the
+// payload doesn't get lost when passing through pipeline stages.
+type QualifiedAddressWith[T any] struct {
+	QualifiedAddressValue
+	Payload T `json:"payload"` // application-specific payload
+}
+
+var (
+	_ QualifiedAddress = (*QualifiedAddressWith[any])(nil)
+	_ Stamper          = (*QualifiedAddressWith[any])(nil)
+)
+
+// WithNewQuality returns newly qualified address information, including the
+// payload.
+func (qa *QualifiedAddressWith[T]) WithNewQuality(q Quality, err error) QualifiedAddress {
+	nqa := *qa
+	nqa.QualifiedAddressValue = *qa.QualifiedAddressValue.WithNewQuality(q, err).(*QualifiedAddressValue)
+	return &nqa
+}
+
+// WithProvenance returns the qualified address with the specified provenance,
+// including the payload.
+func (qa *QualifiedAddressWith[T]) WithProvenance(p *Provenance) QualifiedAddress {
+	nqa := *qa
+	nqa.Provenance = p
+	return &nqa
+}
+
+// MarshalJSON returns the JSON representation of the qualified address with
+// an additional "payload" field.
+func (qa QualifiedAddressWith[T]) MarshalJSON() ([]byte, error) {
+	return marshalWithPayload(qa.QualifiedAddressValue, qa.Payload)
+}
+
+// UnmarshalJSON sets the qualified address and its payload from its JSON
+// representation.
+func (qa *QualifiedAddressWith[T]) UnmarshalJSON(data []byte) error {
+	return unmarshalWithPayload(data, &qa.QualifiedAddressValue, &qa.Payload)
+}
+
+// NamedAddressWith is a named address carrying an application-specific
+// payload of type T alongside. In contrast to embedding [NamedAddressValue]
+// into an application-specific type, NamedAddressWith correctly implements
+// [QualifiedAddress.WithNewQuality], [Stamper] and JSON marshalling, so the
+// payload doesn't get lost when passing through pipeline stages.
+type NamedAddressWith[T any] struct {
+	NamedAddressValue
+	Payload T `json:"payload"` // application-specific payload
+}
+
+var (
+	_ NamedAddress = (*NamedAddressWith[any])(nil)
+	_ Stamper      = (*NamedAddressWith[any])(nil)
+)
+
+// WithNewQuality returns newly qualified named address information, including
+// the payload.
+func (na *NamedAddressWith[T]) WithNewQuality(q Quality, err error) QualifiedAddress {
+	nna := *na
+	nna.NamedAddressValue = *na.NamedAddressValue.WithNewQuality(q, err).(*NamedAddressValue)
+	return &nna
+}
+
+// WithProvenance returns the named address with the specified provenance,
+// including the payload.
+func (na *NamedAddressWith[T]) WithProvenance(p *Provenance) QualifiedAddress {
+	nna := *na
+	nna.Provenance = p
+	return &nna
+}
+
+// MarshalJSON returns the JSON representation of the named address with an
+// additional "payload" field.
+func (na NamedAddressWith[T]) MarshalJSON() ([]byte, error) {
+	return marshalWithPayload(na.NamedAddressValue, na.Payload)
+}
+
+// UnmarshalJSON sets the named address and its payload from its JSON
+// representation.
+func (na *NamedAddressWith[T]) UnmarshalJSON(data []byte) error {
+	return unmarshalWithPayload(data, &na.NamedAddressValue, &na.Payload)
+}
+
+// marshalWithPayload marshals the specified address into a JSON object and then
+// adds the specified payload as a "payload" field to it.
+func marshalWithPayload(addr any, payload any) ([]byte, error) {
+	obj, err := json.Marshal(addr)
+	if err != nil {
+		return nil, err
+	}
+	p, err := json.Marshal(struct {
+		Payload any `json:"payload"`
+	}{Payload: payload})
+	if err != nil {
+		return nil, err
+	}
+	// splice the payload object's field into the address object, that is:
+	// {...} + {"payload":...} -> {...,"payload":...}
+	var b bytes.Buffer
+	b.Write(obj[:len(obj)-1])
+	b.WriteByte(',')
+	b.Write(p[1:])
+	return b.Bytes(), nil
+}
+
+// unmarshalWithPayload unmarshals the specified JSON object into the address as
+// well as its "payload" field into the payload.
+func unmarshalWithPayload[T any](data []byte, addr json.Unmarshaler, payload *T) error {
+	if err := addr.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	var p struct {
+		Payload T `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*payload = p.Payload
+	return nil
+}
@@ -0,0 +1,87 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+type payload struct {
+	Owner string `json:"owner"`
+}
+
+var _ = Describe("payloads", func() {
+
+	It("keeps payloads when changing quality and provenance", func() {
+		na := &NamedAddressWith[payload]{
+			NamedAddressValue: NamedAddressValue{
+				FQDN:                  "foo.net_A.",
+				QualifiedAddressValue: QualifiedAddressValue{Address: "172.24.0.2"},
+			},
+			Payload: payload{Owner: "team-foo"},
+		}
+		verdict := Stamp(na.WithNewQuality(Invalid, errors.New("D'oh!")),
+			NewProvenance(StagePinger, ""))
+		Expect(verdict).To(BeAssignableToTypeOf(na))
+		nna := verdict.(*NamedAddressWith[payload])
+		Expect(nna.Payload.Owner).To(Equal("team-foo"))
+		Expect(nna.Name()).To(Equal("foo.net_A."))
+		Expect(nna.Qual()).To(Equal(Invalid))
+		Expect(nna.Err()).To(MatchError("D'oh!"))
+		Expect(nna.Provenance).To(HaveField("Stage", StagePinger))
+		Expect(na.Qual()).To(Equal(Unverified))
+		Expect(na.Provenance).To(BeNil())
+
+		qa := &QualifiedAddressWith[int]{
+			QualifiedAddressValue: QualifiedAddressValue{Address: "172.24.0.2"},
+			Payload:               42,
+		}
+		Expect(qa.WithNewQuality(Verified, nil)).To(
+			HaveValue(HaveField("Payload", 42)))
+	})
+
+	It("round-trips payloads", func() {
+		na := NamedAddressWith[payload]{
+			NamedAddressValue: NamedAddressValue{
+				FQDN: "foo.net_A.",
+				QualifiedAddressValue: QualifiedAddressValue{
+					Address: "172.24.0.2",
+					Quality: Unreachable,
+					err:     NewAddressError(ErrorUnreachable, errors.New("no replies")),
+				},
+			},
+			Payload: payload{Owner: "team-foo"},
+		}
+		j := Successful(json.Marshal(na))
+		Expect(j).To(MatchJSON(`{
+			"fqdn": "foo.net_A.",
+			"address": "172.24.0.2",
+			"quality": "unreachable",
+			"error": {"kind": "unreachable", "message": "no replies"},
+			"payload": {"owner": "team-foo"}
+		}`))
+		var na2 NamedAddressWith[payload]
+		Expect(json.Unmarshal(j, &na2)).To(Succeed())
+		Expect(na2.Payload).To(Equal(na.Payload))
+		Expect(na2.FQDN).To(Equal(na.FQDN))
+		Expect(na2.Qual()).To(Equal(Unreachable))
+		Expect(na2.Err()).To(MatchError("no replies"))
+
+		qa := QualifiedAddressWith[[]string]{
+			QualifiedAddressValue: QualifiedAddressValue{Address: "172.24.0.2"},
+		}
+		Expect(json.Marshal(qa)).To(MatchJSON(`{
+			"address": "172.24.0.2",
+			"quality": "unverified",
+			"payload": null
+		}`))
+	})
+
+})
@@ -0,0 +1,68 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package types
+
+import "time"
+
+// Stage identifies the processing stage that produced a (named) address
+// update.
+type Stage string
+
+// The stages producing (named) address updates.
+const (
+	StageDigger   Stage = "digger"   // digger announcing and resolving names.
+	StageVerifier Stage = "verifier" // verifier cache replaying cached qualities.
+	StagePinger   Stage = "pinger"   // pinger verifying addresses.
+)
+
+// Provenance records when and by which stage an address update was produced,
+// as well as where it was observed from.
+type Provenance struct {
+	Time   time.Time `json:"time"`             // when the update was produced.
+	Stage  Stage     `json:"stage"`            // which stage produced the update.
+	Origin string    `json:"origin,omitempty"` // network namespace reference or center the update was observed from.
+}
+
+// NewProvenance returns a new Provenance for the specified stage and origin,
+// timestamped with the current time.
+func NewProvenance(stage Stage, origin string) *Provenance {
+	return &Provenance{
+		Time:   time.Now(),
+		Stage:  stage,
+		Origin: origin,
+	}
+}
+
+// Stamper is optionally implemented by [QualifiedAddress] implementations in
+// order to support recording the provenance of address updates.
+type Stamper interface {
+	// WithProvenance returns a new qualified address with the specified
+	// provenance.
+	WithProvenance(p *Provenance) QualifiedAddress
+}
+
+// Stamp returns the specified qualified address with the specified provenance,
+// if the address implements [Stamper]. Otherwise, the address is returned
+// unchanged.
+func Stamp(qa QualifiedAddress, p *Provenance) QualifiedAddress {
+	if stamper, ok := qa.(Stamper); ok {
+		return stamper.WithProvenance(p)
+	}
+	return qa
+}
+
+// WithProvenance returns the qualified address with the specified provenance.
+func (qa *QualifiedAddressValue) WithProvenance(p *Provenance) QualifiedAddress {
+	nqa := *qa
+	nqa.Provenance = p
+	return &nqa
+}
+
+// WithProvenance returns the named address with the specified provenance.
+func (na *NamedAddressValue) WithProvenance(p *Provenance) QualifiedAddress {
+	nna := *na
+	nna.Provenance = p
+	return &nna
+}
@@ -4,7 +4,10 @@
 
 package types
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // Quality indicates the "quality" of a network address, such as unverified,
 // verified, et cetera.
@@ -12,10 +15,12 @@ type Quality int
 
 // The validation qualities of a network address.
 const (
-	Unverified Quality = iota // address neither in verification nor verified.
-	Verifying                 // address in verification.
-	Invalid                   // address could not be successfully verified.
-	Verified                  // address successfully verified.
+	Unverified  Quality = iota // address neither in verification nor verified.
+	Verifying                  // address in verification.
+	Invalid                    // address could not be successfully verified.
+	Verified                   // address successfully verified.
+	Degraded                   // address reachable, but with losses or high round-trip times.
+	Unreachable                // address resolvable, but not reachable.
 )
 
 // String returns the clear-text representation of a Quality value.
@@ -29,17 +34,95 @@ func (q Quality) String() string {
 		return "verified"
 	case Invalid:
 		return "invalid"
+	case Degraded:
+		return "degraded"
+	case Unreachable:
+		return "unreachable"
 	}
 	return fmt.Sprintf("Quality(%d)", q)
 }
 
+// MarshalText returns the clear-text representation of a Quality value, so that
+// Quality values get marshalled into JSON as strings instead of numbers.
+func (q Quality) MarshalText() ([]byte, error) {
+	if q.rank() < 0 {
+		return nil, fmt.Errorf("invalid Quality(%d)", q)
+	}
+	return []byte(q.String()), nil
+}
+
+// UnmarshalText sets a Quality value from its clear-text representation.
+func (q *Quality) UnmarshalText(text []byte) error {
+	for quality := Unverified; quality <= Unreachable; quality++ {
+		if string(text) == quality.String() {
+			*q = quality
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid Quality %q", string(text))
+}
+
+// UnmarshalJSON sets a Quality value from either its clear-text representation
+// or from its plain number, as used by older JSON representations.
+func (q *Quality) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return q.UnmarshalText([]byte(text))
+	}
+	var num int
+	if err := json.Unmarshal(data, &num); err != nil {
+		return fmt.Errorf("invalid Quality %s", string(data))
+	}
+	if Quality(num).rank() < 0 {
+		return fmt.Errorf("invalid Quality(%d)", num)
+	}
+	*q = Quality(num)
+	return nil
+}
+
 // IsPending returns true as long as an address hasn't been either successfully
-// or unsuccessfully verified.
+// or unsuccessfully verified, that is, while it is still unverified or in
+// verification.
 func (q Quality) IsPending() bool {
 	switch q {
-	case Unverified, Verified:
+	case Unverified, Verifying:
 		return true
 	default:
 		return false
 	}
 }
+
+// Supersedes returns true if the quality q is to replace the older quality
+// old, that is, if q differs from old and changing from old to q is a legal
+// quality transition as checked by [Transition].
+func (q Quality) Supersedes(old Quality) bool {
+	return q != old && Transition(old, q) == nil
+}
+
+// Better returns true if the quality q is better than the other quality, in
+// the order of unverified, verifying, invalid, unreachable, degraded, and
+// finally verified. As verdicts may change into any other verdict, a quality
+// superseding another one isn't necessarily better.
+func (q Quality) Better(other Quality) bool {
+	return q.rank() > other.rank()
+}
+
+// rank returns the position of a quality in the order of qualities, with the
+// pending qualities first, or -1 for unknown qualities.
+func (q Quality) rank() int {
+	switch q {
+	case Unverified:
+		return 0
+	case Verifying:
+		return 1
+	case Invalid:
+		return 2
+	case Unreachable:
+		return 3
+	case Degraded:
+		return 4
+	case Verified:
+		return 5
+	}
+	return -1
+}
@@ -0,0 +1,26 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package types
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("qualities", func() {
+
+	DescribeTable("tells pending from final qualities",
+		func(q Quality, pending bool) {
+			Expect(q.IsPending()).To(Equal(pending))
+		},
+		Entry(nil, Unverified, true),
+		Entry(nil, Verifying, true),
+		Entry(nil, Invalid, false),
+		Entry(nil, Verified, false),
+		Entry(nil, Degraded, false),
+		Entry(nil, Unreachable, false),
+	)
+
+})
@@ -0,0 +1,51 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package types
+
+import "fmt"
+
+// TransitionError reports an illegal quality transition, such as from
+// [Verified] back to [Verifying].
+type TransitionError struct {
+	From Quality // quality before the transition.
+	To   Quality // rejected new quality.
+}
+
+// Error returns a human-readable description of the illegal transition.
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("illegal quality transition from %s to %s", e.From, e.To)
+}
+
+// Transition checks whether an address may change its quality from the quality
+// from to the quality to, returning nil if the transition is legal and a
+// [*TransitionError] otherwise. The legal transitions are:
+//   - from unverified to any quality,
+//   - from verifying to any verdict, that is, invalid, unreachable, degraded,
+//     or verified,
+//   - from a verdict to any other verdict, such as when re-verifying an
+//     address finds it to have become unreachable.
+//
+// Transitions to the same quality are legal, yet don't change anything. The
+// only illegal transitions are fall-backs to pending qualities: verdicts never
+// fall back to unverified or verifying, and verifying never falls back to
+// unverified.
+func Transition(from, to Quality) error {
+	if from.rank() < 0 || to.rank() < 0 || (to.IsPending() && to.rank() < from.rank()) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
+
+// Transit returns the specified qualified address with its quality changed to
+// q and its error set to err, as [QualifiedAddress.WithNewQuality] does.
+// However, if the quality transition is illegal as checked by [Transition],
+// then Transit returns the unchanged qualified address together with a
+// [*TransitionError].
+func Transit(qa QualifiedAddress, q Quality, err error) (QualifiedAddress, error) {
+	if terr := Transition(qa.Qual(), q); terr != nil {
+		return qa, terr
+	}
+	return qa.WithNewQuality(q, err), nil
+}
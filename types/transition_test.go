@@ -0,0 +1,86 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package types
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("quality transitions", func() {
+
+	DescribeTable("checks transitions",
+		func(from, to Quality, legal bool) {
+			err := Transition(from, to)
+			if legal {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			var terr *TransitionError
+			Expect(errors.As(err, &terr)).To(BeTrue())
+			Expect(terr.From).To(Equal(from))
+			Expect(terr.To).To(Equal(to))
+		},
+		Entry(nil, Unverified, Verifying, true),
+		Entry(nil, Unverified, Verified, true),
+		Entry(nil, Verifying, Verifying, true),
+		Entry(nil, Verifying, Invalid, true),
+		Entry(nil, Verifying, Unreachable, true),
+		Entry(nil, Invalid, Verified, true),
+		Entry(nil, Degraded, Verified, true),
+		Entry(nil, Verified, Degraded, true),
+		Entry(nil, Verified, Unreachable, true),
+		Entry(nil, Unreachable, Invalid, true),
+		Entry(nil, Verifying, Unverified, false),
+		Entry(nil, Verified, Verifying, false),
+		Entry(nil, Verified, Unverified, false),
+		Entry(nil, Invalid, Verifying, false),
+		Entry(nil, Verifying, Quality(42), false),
+	)
+
+	It("supersedes only on changing legal transitions", func() {
+		Expect(Verified.Supersedes(Verifying)).To(BeTrue())
+		Expect(Verified.Supersedes(Verified)).To(BeFalse())
+		Expect(Unreachable.Supersedes(Verified)).To(BeTrue())
+		Expect(Verifying.Supersedes(Verified)).To(BeFalse())
+	})
+
+	It("orders qualities", func() {
+		Expect(Verified.Better(Degraded)).To(BeTrue())
+		Expect(Unreachable.Better(Verified)).To(BeFalse())
+		Expect(Verified.Better(Verified)).To(BeFalse())
+	})
+
+	DescribeTable("sets errors with new qualities",
+		func(qa QualifiedAddress) {
+			qa = qa.WithNewQuality(Invalid, errors.New("D'oh!"))
+			Expect(qa.Qual()).To(Equal(Invalid))
+			Expect(qa.Err()).To(MatchError("D'oh!"))
+			qa = qa.WithNewQuality(Verified, nil)
+			Expect(qa.Qual()).To(Equal(Verified))
+			Expect(qa.Err()).NotTo(HaveOccurred())
+		},
+		Entry("qualified address", &QualifiedAddressValue{Address: "127.0.0.1"}),
+		Entry("named address", &NamedAddressValue{
+			FQDN: "localhost", QualifiedAddressValue: QualifiedAddressValue{Address: "127.0.0.1"}}),
+		Entry("qualified address with payload", &QualifiedAddressWith[int]{
+			QualifiedAddressValue: QualifiedAddressValue{Address: "127.0.0.1"}}),
+	)
+
+	It("transits only legally", func() {
+		qa := QualifiedAddress(&QualifiedAddressValue{Address: "127.0.0.1", Quality: Verified})
+		nqa, err := Transit(qa, Verifying, nil)
+		Expect(err).To(MatchError("illegal quality transition from verified to verifying"))
+		Expect(nqa).To(BeIdenticalTo(qa))
+
+		nqa, err = Transit(qa.WithNewQuality(Verifying, nil), Unreachable, errors.New("no replies"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nqa.Qual()).To(Equal(Unreachable))
+		Expect(nqa.Err()).To(MatchError("no replies"))
+	})
+
+})
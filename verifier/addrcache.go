@@ -11,27 +11,41 @@ import (
 	"github.com/siemens/mobydig/types"
 )
 
+// NamedAddressCacheOf caches named qualified addresses of type N so that
+// unnecessary duplicate address validations can be avoided, yet validation
+// results distributed at once to all named addresses pending in verification.
+type NamedAddressCacheOf[N types.NamedAddress] struct {
+	mu sync.Mutex
+	m  map[string]qualityUpdateConsumers[N] // IP address -> list of pending FQDN consumers
+}
+
 // NamedAddressCache caches named qualified addresses so that unnecessary duplicate
 // address validations can be avoided, yet validation results distributed at
 // once to all named addresses pending in verification.
-type NamedAddressCache struct {
-	mu sync.Mutex
-	m  map[string]qualityUpdateConsumers // IP address -> list of pending FQDN consumers
-}
+type NamedAddressCache = NamedAddressCacheOf[types.NamedAddress]
 
 // NewNamedAddressCache returns a new NamedAddressCache object.
 func NewNamedAddressCache() *NamedAddressCache {
-	return &NamedAddressCache{
-		m: map[string]qualityUpdateConsumers{},
+	return NewNamedAddressCacheOf[types.NamedAddress]()
+}
+
+// NewNamedAddressCacheOf returns a new NamedAddressCacheOf object for named
+// addresses of type N.
+func NewNamedAddressCacheOf[N types.NamedAddress]() *NamedAddressCacheOf[N] {
+	return &NamedAddressCacheOf[N]{
+		m: map[string]qualityUpdateConsumers[N]{},
 	}
 }
 
-// qualityConsumers is a list of FQDNs that map to the same underlying IP
-// address and thus want to learn about any updates in that IP address' quality.
-type qualityUpdateConsumers struct {
+// qualityConsumers is a list of named addresses that map to the same underlying
+// IP address and thus want to learn about any updates in that IP address'
+// quality. We keep the named addresses as originally seen instead of only
+// their FQDNs, so quality updates retain the per-name information, such as
+// CNAMEs and payloads.
+type qualityUpdateConsumers[N types.NamedAddress] struct {
 	q         types.Quality
-	err       error    // optional error reason for invalid quality
-	consumers []string // waiting FQDNs that want to consume quality updates.
+	err       error // optional error reason for invalid quality
+	consumers []N   // waiting named addresses that want to consume quality updates.
 }
 
 // Update checks the specified named address to see if it is a new (unverified)
@@ -39,10 +53,10 @@ type qualityUpdateConsumers struct {
 // address to the caller, so that the caller, for instance, can start validating
 // the new address. Update returns false if the (unverified) address has already
 // be seen, and the name for this address is cached. If the address is already
-// in the cache and its quality is a final verdict of Verified or Invalid, then
+// in the cache and its quality is a final verdict, such as Verified or Invalid, then
 // this update is automatically sent to the news consumer for all FQDNs
 // associated with this address.
-func (c *NamedAddressCache) Update(ctx context.Context, namaddr types.NamedAddress, news chan<- types.NamedAddress) bool {
+func (c *NamedAddressCacheOf[N]) Update(ctx context.Context, namaddr N, news chan<- N) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	addr := namaddr.Addr()
@@ -54,9 +68,9 @@ func (c *NamedAddressCache) Update(ctx context.Context, namaddr types.NamedAddre
 		// Note: we assume that a new address always enters in qualities
 		// Unverified or Verifying, so there will always be a later quality
 		// update to be expected.
-		c.m[addr] = qualityUpdateConsumers{
+		c.m[addr] = qualityUpdateConsumers[N]{
 			q:         namaddr.Qual(),
-			consumers: []string{namaddr.Name()},
+			consumers: []N{namaddr},
 		}
 		select {
 		case news <- namaddr:
@@ -70,35 +84,36 @@ func (c *NamedAddressCache) Update(ctx context.Context, namaddr types.NamedAddre
 	knownConsumer := false
 	fqdn := namaddr.Name()
 	for _, consumer := range qc.consumers {
-		if consumer == fqdn {
+		if consumer.Name() == fqdn {
 			knownConsumer = true
 		}
 	}
-	if namaddr.Qual() <= qc.q {
+	if !namaddr.Qual().Supersedes(qc.q) {
 		// send an update with the most recent quality known, as the state
-		// specified in the Update is already stale. We only need to inform
-		// about this specific FQDN, no other consumers affected.
+		// specified in the Update is either the same or already stale, see
+		// also [types.Transition]. We only need to inform about this specific
+		// FQDN, no other consumers affected.
 		if !knownConsumer {
-			qc.consumers = append(qc.consumers, fqdn)
+			qc.consumers = append(qc.consumers, namaddr)
 			c.m[addr] = qc
 			select {
-			case news <- namaddr.WithNewQuality(qc.q, qc.err).(types.NamedAddress):
+			case news <- types.Stamp(namaddr.WithNewQuality(qc.q, qc.err), stamp(namaddr)).(N):
 			case <-ctx.Done():
 			}
 		}
 		return false
 	}
-	// update quality
-	qc.q = namaddr.Qual()
+	// update quality and its explanation, if any.
+	qc.q, qc.err = namaddr.Qual(), namaddr.Err()
 	// This address is already known, so now check if it is in validation or
 	// not. If in validation, then register the current FQDN as a consumer for a
 	// later quality update (if not already registered). If already
 	// (in)validated, notify all registered consumers.
-	var consumers []string
+	var consumers []N
 	switch qc.q {
 	case types.Unverified, types.Verifying:
 		if !knownConsumer {
-			qc.consumers = append(qc.consumers, fqdn)
+			qc.consumers = append(qc.consumers, namaddr)
 		}
 		consumers = qc.consumers
 	default:
@@ -110,17 +125,28 @@ func (c *NamedAddressCache) Update(ctx context.Context, namaddr types.NamedAddre
 		consumers, qc.consumers = qc.consumers, nil
 	}
 	c.m[addr] = qc // update cache with most recent quality and consumers.
-	// notify all registered consumers of this quality update.
-	templ := namaddr.NA()
-	templ.Quality = namaddr.Qual()
+	// notify all registered consumers of this quality update, keeping the
+	// provenance of the update, such as the pinger's, so that timelines tell
+	// when and by whom the verdict was actually produced.
+	provenance := namaddr.QA().Provenance
 	for _, consumer := range consumers {
-		templ := templ
-		templ.FQDN = consumer
+		update := types.Stamp(consumer.WithNewQuality(namaddr.Qual(), namaddr.Err()), provenance).(N)
 		select {
-		case news <- &templ:
+		case news <- update:
 		case <-ctx.Done(): // bail out immediately.
 			return false
 		}
 	}
 	return false
 }
+
+// stamp returns a new provenance for an update the cache replays from its
+// cached quality instead of passing on, keeping the origin of the specified
+// update, if known.
+func stamp(namaddr types.NamedAddress) *types.Provenance {
+	var origin string
+	if p := namaddr.NA().Provenance; p != nil {
+		origin = p.Origin
+	}
+	return types.NewProvenance(types.StageVerifier, origin)
+}
@@ -0,0 +1,85 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package verifier
+
+import (
+	"context"
+
+	"github.com/siemens/mobydig/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type payload struct {
+	Owner string
+}
+
+var _ = Describe("named address cache", func() {
+
+	It("keeps the payloads of named addresses", func(ctx context.Context) {
+		namaddr := func(fqdn string, q types.Quality, owner string) *types.NamedAddressWith[payload] {
+			return &types.NamedAddressWith[payload]{
+				NamedAddressValue: types.NamedAddressValue{
+					FQDN:                  fqdn,
+					QualifiedAddressValue: types.QualifiedAddressValue{Address: "172.24.0.2", Quality: q},
+				},
+				Payload: payload{Owner: owner},
+			}
+		}
+		cache := NewNamedAddressCacheOf[*types.NamedAddressWith[payload]]()
+		news := make(chan *types.NamedAddressWith[payload], 10)
+
+		By("caching a new address and another name for it")
+		Expect(cache.Update(ctx, namaddr("foo.net_A.", types.Verifying, "team-foo"), news)).To(BeTrue())
+		Expect(cache.Update(ctx, namaddr("bar.net_A.", types.Verifying, "team-bar"), news)).To(BeFalse())
+		Expect(news).To(Receive(HaveField("Payload.Owner", "team-foo")))
+		Expect(news).To(Receive(HaveField("Payload.Owner", "team-bar")))
+
+		By("distributing the verdict to both names")
+		Expect(cache.Update(ctx, namaddr("foo.net_A.", types.Verified, "team-foo"), news)).To(BeFalse())
+		var first, second *types.NamedAddressWith[payload]
+		Expect(news).To(Receive(&first))
+		Expect(news).To(Receive(&second))
+		Expect([]*types.NamedAddressWith[payload]{first, second}).To(ConsistOf(
+			And(HaveField("FQDN", "foo.net_A."), HaveField("Payload.Owner", "team-foo"),
+				HaveField("QualifiedAddressValue.Quality", types.Verified)),
+			And(HaveField("FQDN", "bar.net_A."), HaveField("Payload.Owner", "team-bar"),
+				HaveField("QualifiedAddressValue.Quality", types.Verified)),
+		))
+	})
+
+	It("keeps the provenance of verdicts", func(ctx context.Context) {
+		namaddr := func(fqdn string, q types.Quality, p *types.Provenance) *types.NamedAddressValue {
+			return &types.NamedAddressValue{
+				FQDN: fqdn,
+				QualifiedAddressValue: types.QualifiedAddressValue{
+					Address: "172.24.0.2", Quality: q, Provenance: p},
+			}
+		}
+		cache := NewNamedAddressCache()
+		news := make(chan types.NamedAddress, 10)
+
+		dug := types.NewProvenance(types.StageDigger, "/proc/42/ns/net")
+		Expect(cache.Update(ctx, namaddr("foo.net_A.", types.Unverified, dug), news)).To(BeTrue())
+		Expect(news).To(Receive(HaveField("QA().Provenance", BeIdenticalTo(dug))))
+
+		By("passing on the pinger's verdict")
+		pinged := types.NewProvenance(types.StagePinger, "/proc/42/ns/net")
+		Expect(cache.Update(ctx, namaddr("foo.net_A.", types.Verified, pinged), news)).To(BeFalse())
+		Expect(news).To(Receive(HaveField("QA().Provenance", BeIdenticalTo(pinged))))
+
+		By("replaying the cached verdict for another name")
+		Expect(cache.Update(ctx, namaddr("bar.net_A.", types.Unverified, dug), news)).To(BeFalse())
+		Expect(news).To(Receive(And(
+			HaveField("Name()", "bar.net_A."),
+			HaveField("Qual()", types.Verified),
+			HaveField("QA().Provenance", And(
+				HaveField("Stage", types.StageVerifier),
+				HaveField("Origin", "/proc/42/ns/net"))),
+		)))
+	})
+
+})
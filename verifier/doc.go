@@ -3,5 +3,10 @@ Package verifier implements an IP address verifier with caching in order to
 avoid expensive duplicate IP address verification.
 
 The concrete IP address verification is then carried out by a Pinger.
+
+[VerifierOf] is the generic form of [Verifier] for a concrete type of named
+addresses, such as [types.NamedAddressWith] carrying an application-specific
+payload. Named addresses sharing the same IP address each keep their own
+payloads when the verification result is distributed to them.
 */
 package verifier
@@ -11,24 +11,43 @@ import (
 	"github.com/siemens/mobydig/types"
 )
 
+// VerifierOf verifies a stream of named addresses of type N, caching
+// verification results as to avoiding unnecessary duplicate verification
+// attempts. It uses a PingerOf for verifying the IP addresses.
+//
+// N is the concrete type of named addresses to verify, such as
+// [types.NamedAddressWith] carrying an application-specific payload, which
+// then is passed through the verifier without the need for type assertions.
+type VerifierOf[N types.NamedAddress] struct {
+	news    chan<- N
+	pinger  *ping.PingerOf[N]
+	checked <-chan N
+}
+
 // Verifier verifies a stream of named addresses, caching verification results
 // as to avoiding unnecessary duplicate verification attempts. It uses a Pinger
 // for verifying the IP addresses.
-type Verifier struct {
-	news    chan<- types.NamedAddress
-	pinger  *ping.Pinger
-	checked <-chan types.QualifiedAddress
-}
+type Verifier = VerifierOf[types.NamedAddress]
 
 // New returns a new Verifier that verifies addresses from the perspective of
 // the specified network namespace with a maximum number of parallel
 // verification workers. If the network namespace reference netnsref is zero,
 // then the verification will be carried out in the process' original network
 // namespace.
-func New(size int, netnsref string) (*Verifier, <-chan types.NamedAddress) {
-	news := make(chan types.NamedAddress, size)
-	pinger, checked := ping.New(size, ping.InNetworkNamespace(netnsref))
-	return &Verifier{
+//
+// Additional [ping.PingerOption]s are passed on to the [ping.Pinger] carrying
+// out the verification, such as [ping.WithTCPProbe].
+func New(size int, netnsref string, options ...ping.PingerOption) (*Verifier, <-chan types.NamedAddress) {
+	return NewOf[types.NamedAddress](size, netnsref, options...)
+}
+
+// NewOf returns a new VerifierOf for named addresses of type N, and otherwise
+// works the same as [New].
+func NewOf[N types.NamedAddress](size int, netnsref string, options ...ping.PingerOption) (*VerifierOf[N], <-chan N) {
+	news := make(chan N, size)
+	pinger, checked := ping.NewOf[N](size,
+		append([]ping.PingerOption{ping.InNetworkNamespace(netnsref)}, options...)...)
+	return &VerifierOf[N]{
 		news:    news,
 		pinger:  pinger,
 		checked: checked,
@@ -42,9 +61,9 @@ func New(size int, netnsref string) (*Verifier, <-chan types.NamedAddress) {
 //
 // In case the specified context is cancelled, then Verify will stop pulling off
 // new verification tasks and return as soon as possible, closing the output
-// channel.
-func (v *Verifier) Verify(ctx context.Context, in <-chan types.NamedAddress) {
-	addrcache := NewNamedAddressCache()
+// channel without sending any further named addresses.
+func (v *VerifierOf[N]) Verify(ctx context.Context, in <-chan N) {
+	addrcache := NewNamedAddressCacheOf[N]()
 	// As soon as new validation results trickle in, update the cache so that
 	// the cache can inform the consumer of this Validator of the results.
 	done := make(chan struct{}, 1) // fire and forget, and never block.
@@ -56,7 +75,7 @@ func (v *Verifier) Verify(ctx context.Context, in <-chan types.NamedAddress) {
 				if !ok {
 					break slurpTasks
 				}
-				addrcache.Update(ctx, qaddr.(types.NamedAddress), v.news)
+				addrcache.Update(ctx, qaddr, v.news)
 			case <-ctx.Done():
 				break slurpTasks
 			}
@@ -96,12 +115,10 @@ slurpPingerVerdicts:
 	}
 	v.pinger.StopWait()
 	// wait for all verification results to have come through and passed on
-	// before calling it a day. In case the context was cancelled we don't wait
-	// for the done signal, but immediately close our "outlet".
-	select {
-	case <-ctx.Done():
-	default:
-		<-done
-	}
+	// before calling it a day. Even in case the context was cancelled we need
+	// to wait for the done signal, as the cache might otherwise still try to
+	// send on our "outlet" after we've closed it. As all sends select on the
+	// context, this won't block for long.
+	<-done
 	close(v.news)
 }
@@ -0,0 +1,64 @@
+// (c) Siemens AG 2023
+//
+// SPDX-License-Identifier: MIT
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/siemens/mobydig/ping"
+	"github.com/siemens/mobydig/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+)
+
+var _ = Describe("verifier", func() {
+
+	BeforeEach(func() {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(3 * time.Second).ProbeEvery(250 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	It("shuts down on cancellation with a full output buffer", NodeTimeout(30*time.Second), func(ctx context.Context) {
+		for i := 0; i < 100; i++ {
+			// Feed the verifier with verdicts of our own instead of waiting
+			// for pings, so that there always are verdicts left to pass on
+			// into the full output buffer when cancelling.
+			pinger, _ := ping.NewOf[types.NamedAddress](1)
+			checked := make(chan types.NamedAddress, 100)
+			news := make(chan types.NamedAddress, 1)
+			v := &Verifier{news: news, pinger: pinger, checked: checked}
+			for j := 0; j < cap(checked); j++ {
+				checked <- &types.NamedAddressValue{
+					FQDN: fmt.Sprintf("foo-%d.net_A.", j),
+					QualifiedAddressValue: types.QualifiedAddressValue{
+						Address: fmt.Sprintf("127.0.0.%d", j),
+						Quality: types.Verified,
+					},
+				}
+			}
+			in := make(chan types.NamedAddress)
+			vctx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				v.Verify(vctx, in)
+			}()
+
+			Eventually(func() int { return len(news) }).Should(Equal(1))
+			cancel()
+			Eventually(done).Within(5 * time.Second).Should(BeClosed())
+			Expect(news).To(Receive())
+			Expect(news).To(BeClosed())
+		}
+	})
+
+})